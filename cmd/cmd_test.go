@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/patitolabs/gosuv2"
+	"github.com/patitolabs/suvctl/util"
+	"github.com/patitolabs/suvctl/util/suvtest"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// execute runs suvctl with args against the config file cfg and returns its stdout
+func execute(t *testing.T, cfg string, args ...string) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	output := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		output <- string(data)
+	}()
+
+	resetFlags(rootCmd)
+	rootCmd.SetArgs(append([]string{"--config", cfg, "--credential-store", "plaintext"}, args...))
+	err = rootCmd.Execute()
+
+	w.Close()
+	out := <-output
+
+	if err != nil {
+		t.Fatalf("suvctl %s: %v", strings.Join(args, " "), err)
+	}
+
+	return out
+}
+
+// resetFlags sets the flags of cmd and its subcommands back to their defaults, as cobra
+// keeps them between runs
+func resetFlags(cmd *cobra.Command) {
+	reset := func(flag *pflag.Flag) {
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			slice.Replace(nil)
+		} else {
+			flag.Value.Set(flag.DefValue)
+		}
+		flag.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+
+	for _, sub := range cmd.Commands() {
+		resetFlags(sub)
+	}
+}
+
+// executeJSON runs suvctl with --output raw and decodes its stdout into v
+func executeJSON(t *testing.T, cfg string, v any, args ...string) {
+	t.Helper()
+
+	out := execute(t, cfg, append([]string{"--output", "raw"}, args...)...)
+	if err := json.Unmarshal([]byte(out), v); err != nil {
+		t.Fatalf("suvctl %s: %v", strings.Join(args, " "), err)
+	}
+}
+
+// loggedIn starts a stand-in SUV server, points suvctl to it and logs in with the
+// credentials from the environment, returning the server and the config file to use
+func loggedIn(t *testing.T) (*suvtest.Server, string) {
+	t.Helper()
+
+	server := suvtest.NewServer(suvtest.DefaultFixtures())
+	t.Cleanup(server.Close)
+
+	backend := newBackend
+	newBackend = func(config *gosuv2.SuvConfig) util.Backend {
+		return server.Backend(false)
+	}
+	t.Cleanup(func() { newBackend = backend })
+
+	dir := t.TempDir()
+	cfg := filepath.Join(dir, "config.yml")
+	historyFile := filepath.Join(dir, "history.db")
+	if err := os.WriteFile(cfg, []byte("history_file: "+historyFile+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SUVCTL_USERCODE", "1023300121")
+	t.Setenv("SUVCTL_PASSWORD", "correct-horse")
+
+	if out := execute(t, cfg, "login"); !strings.Contains(out, "Login successful") {
+		t.Fatalf("login printed %q", out)
+	}
+
+	return server, cfg
+}
+
+func TestLogout(t *testing.T) {
+	_, cfg := loggedIn(t)
+
+	if out := execute(t, cfg, "logout"); !strings.Contains(out, "Logout successful") {
+		t.Fatalf("logout printed %q", out)
+	}
+}
+
+func TestGrades(t *testing.T) {
+	_, cfg := loggedIn(t)
+
+	var grades []util.GradeData
+	executeJSON(t, cfg, &grades, "grades")
+	if len(grades) != 4 || grades[0].CourseName != "CÁLCULO DIFERENCIAL E INTEGRAL" {
+		t.Fatalf("unexpected grades: %+v", grades)
+	}
+}
+
+func TestGradesRenewsExpiredSession(t *testing.T) {
+	server, cfg := loggedIn(t)
+
+	// An expired session is renewed with the credentials from the environment
+	server.Expire()
+
+	var grades []util.GradeData
+	executeJSON(t, cfg, &grades, "grades", "--courseid", "3402")
+	if len(grades) != 1 || grades[0].FinalStatus != "FAILED" {
+		t.Fatalf("unexpected grades after the session expired: %+v", grades)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	_, cfg := loggedIn(t)
+
+	var students []util.StudentData
+	executeJSON(t, cfg, &students, "search", "--code", "1023300245")
+	if len(students) != 1 || students[0].DNI != "72345678" {
+		t.Fatalf("unexpected students: %+v", students)
+	}
+}
+
+func TestWhatif(t *testing.T) {
+	_, cfg := loggedIn(t)
+
+	var projections []util.Projection
+	executeJSON(t, cfg, &projections, "grades", "whatif", "--courseid", "3403")
+	if len(projections) != 1 || projections[0].Needed != 11.78 {
+		t.Fatalf("unexpected projections: %+v", projections)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	_, cfg := loggedIn(t)
+
+	var summary util.Summary
+	executeJSON(t, cfg, &summary, "grades", "summary")
+	if summary.Courses != 4 || summary.Passed != 1 || len(summary.AtRisk) != 2 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	_, cfg := loggedIn(t)
+
+	// Every fetch records the grades, the second one finds them unchanged
+	execute(t, cfg, "grades")
+	execute(t, cfg, "grades")
+
+	t.Run("entries", func(t *testing.T) {
+		var entries []util.HistoryEntry
+		executeJSON(t, cfg, &entries, "grades", "history", "--courseid", "3401")
+		if len(entries) == 0 || entries[0].CourseID != 3401 {
+			t.Fatalf("unexpected grade history: %+v", entries)
+		}
+	})
+
+	t.Run("snapshots", func(t *testing.T) {
+		var snapshots []util.SnapshotInfo
+		executeJSON(t, cfg, &snapshots, "grades", "history", "--snapshots")
+		// The grades did not change between fetches, so they were only recorded once
+		if len(snapshots) != 1 || snapshots[0].ID != 1 || snapshots[0].Courses != 4 {
+			t.Fatalf("unexpected snapshots: %+v", snapshots)
+		}
+	})
+
+	t.Run("diff", func(t *testing.T) {
+		if out := execute(t, cfg, "grades", "diff", "--from", "1", "--to", "-1"); out != "No changes.\n" {
+			t.Fatalf("grades diff printed %q", out)
+		}
+	})
+}
+
+func TestDiffFirstRun(t *testing.T) {
+	_, cfg := loggedIn(t)
+
+	// With no earlier snapshot nothing changed, rather than every course being added
+	if out := execute(t, cfg, "grades", "diff"); out != "No changes.\n" {
+		t.Fatalf("grades diff printed %q", out)
+	}
+
+	var snapshots []util.SnapshotInfo
+	executeJSON(t, cfg, &snapshots, "grades", "history", "--snapshots")
+	if len(snapshots) != 1 {
+		t.Fatalf("the first diff recorded %d snapshots, want 1", len(snapshots))
+	}
+}
+
+func TestReport(t *testing.T) {
+	_, cfg := loggedIn(t)
+
+	pdf := filepath.Join(t.TempDir(), "grades.pdf")
+	execute(t, cfg, "grades", "report", "--pdf", "--out", pdf)
+	if data, err := os.ReadFile(pdf); err != nil || !strings.HasPrefix(string(data), "%PDF-") {
+		t.Fatalf("grades report did not write a PDF: %v", err)
+	}
+}
+
+func TestProfile(t *testing.T) {
+	cfg := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(cfg, []byte("host: suv.example.com\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The results go to stdout, so that they can be piped
+	if out := execute(t, cfg, "profile", "add", "work", "--use"); out != "Profile work added\nUsing profile work\n" {
+		t.Fatalf("profile add printed %q", out)
+	}
+	if out := execute(t, cfg, "profile", "list"); out != "  default\n* work\n" {
+		t.Fatalf("profile list printed %q", out)
+	}
+	if out := execute(t, cfg, "profile", "show"); !strings.Contains(out, "Name: work\nActive: true\n") {
+		t.Fatalf("profile show printed %q", out)
+	}
+	if out := execute(t, cfg, "profile", "remove", "work"); out != "Profile work removed\n" {
+		t.Fatalf("profile remove printed %q", out)
+	}
+}
+
+func TestSessionStatus(t *testing.T) {
+	_, cfg := loggedIn(t)
+
+	if out := execute(t, cfg, "session", "status"); out != "The session of profile default is alive\n" {
+		t.Fatalf("session status printed %q", out)
+	}
+}
+
+func TestInvalidNotifySettings(t *testing.T) {
+	cfg := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(cfg, []byte("notify:\n  - type: pigeon\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the commands sending notifications read the notify section
+	if out := execute(t, cfg, "profile", "list"); out != "* default\n" {
+		t.Fatalf("profile list printed %q", out)
+	}
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/patitolabs/suvctl/util"
+	"github.com/spf13/cobra"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Show what changed in the grades since the last run",
+	Long: `Show the grades that were added, changed or removed since the last snapshot of the
+grade history, by comparing it with the grades fetched now.
+
+--from and --to compare other snapshots instead, named by their ID from
+grades history --snapshots (negative IDs count back from the last one) or by a
+date, which names the last snapshot taken up to then. Without --to, the snapshot
+from --from is compared with the grades fetched now.
+
+Like diff, it exits with 0 when nothing changed, 1 when something did and 2 or
+more, as every command does on errors, when the grades could not be compared.
+The first run, with no earlier snapshot, only starts the history and exits with 0.
+With --notify, changes are also sent to the notifiers in the notify section of
+the config file.`,
+	Example: `  suvctl grades diff
+  suvctl grades diff --from 2025-10-01
+  suvctl grades diff --from 1 --to -1 --courseid 3403 -o json
+  suvctl grades diff --notify > /dev/null`,
+	Args: cobra.NoArgs,
+	Run:  diff,
+}
+
+func init() {
+	gradesCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().String("from", "", "snapshot to compare from, by ID or date (default: the last one)")
+	diffCmd.Flags().String("to", "", "snapshot to compare to, by ID or date (default: the grades fetched now)")
+	diffCmd.Flags().Bool("notify", false, "send the changes to the notifiers in the config file")
+}
+
+func diff(cmd *cobra.Command, args []string) {
+	from, _ := cmd.Flags().GetString("from")
+	to, _ := cmd.Flags().GetString("to")
+
+	var notifiers util.Notifiers
+	if notify, _ := cmd.Flags().GetBool("notify"); notify {
+		var err error
+		notifiers, err = util.GetNotifiers()
+		checkErr(err)
+	}
+
+	if to == "" {
+		loadSession()
+	}
+
+	changes, err := c.DiffGrades(cmd.Context(), from, to, gradeFilter(cmd))
+	if errors.Is(err, util.ErrNoEarlierSnapshot) {
+		// A first run has nothing to compare with, which is not a change
+		fmt.Fprintln(os.Stderr, "No earlier snapshot to compare with, the grades fetched now start the history")
+		changes, err = []util.GradeChange{}, nil
+	}
+	checkErr(err)
+
+	checkErr(util.OutputChanges(os.Stdout, changes, output))
+
+	if len(changes) > 0 {
+		notifyChanges(cmd.Context(), notifiers, changes)
+		os.Exit(util.ExitCheckFailed)
+	}
+}
+
+// notifyChanges sends changes to notifiers, warning about the ones that fail
+func notifyChanges(ctx context.Context, notifiers util.Notifiers, changes []util.GradeChange) {
+	if err := notifiers.Notify(ctx, util.NewNotification(changes)); err != nil {
+		fmt.Fprintln(os.Stderr, "Warning: sending the notifications failed:", err)
+	}
+}
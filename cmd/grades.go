@@ -1,36 +1,84 @@
 package cmd
 
-import "github.com/spf13/cobra"
+import (
+	"os"
+	"strconv"
+
+	"github.com/patitolabs/suvctl/util"
+	"github.com/spf13/cobra"
+)
 
 var gradesCmd = &cobra.Command{
 	Use:   "grades",
 	Short: "List the grades of the current period",
-	Run:   grades,
+	Long: `List the grades of the current period.
+
+--fail-on makes suvctl exit with 1 when a course has one of the given final
+statuses, failed or pending, after listing the grades, so that scripts and CI
+checks can act on it.`,
+	Example: `  suvctl grades
+  suvctl grades --courseid 3401 -o json
+  suvctl grades --fail-on failed,pending -o text`,
+	Run: grades,
+}
+
+// failOnStatuses are the final statuses --fail-on takes, by their name in the flag
+var failOnStatuses = map[string]string{
+	"failed":  util.StatusFailed,
+	"pending": util.StatusPending,
 }
 
 func init() {
 	rootCmd.AddCommand(gradesCmd)
 
-	gradesCmd.Flags().StringArrayP("courseid", "i", []string{}, "Filter by course ID")
-	gradesCmd.Flags().StringArrayP("course", "n", []string{}, "Filter by course name")
+	gradesCmd.PersistentFlags().StringArrayP("courseid", "i", []string{}, "Filter by course ID")
+	gradesCmd.PersistentFlags().StringArrayP("course", "n", []string{}, "Filter by course name")
+	gradesCmd.Flags().StringSlice("fail-on", nil, "exit with 1 when a course is failed or pending")
 }
 
 func grades(cmd *cobra.Command, args []string) {
-	courseIds, err := cmd.Flags().GetStringArray("courseid")
-	cobra.CheckErr(err)
-
-	courseNames, err := cmd.Flags().GetStringArray("course")
-	cobra.CheckErr(err)
+	failOn, err := cmd.Flags().GetStringSlice("fail-on")
+	checkErr(err)
 
-	if len(courseIds) > 0 {
-		c.ListGradesByCourseId(courseIds)
+	statuses := map[string]bool{}
+	for _, name := range failOn {
+		status, ok := failOnStatuses[name]
+		if !ok {
+			checkErr(util.Errorf(util.KindUsage, "invalid --fail-on %q (use failed or pending)", name))
+		}
+		statuses[status] = true
 	}
 
-	if len(courseNames) > 0 {
-		c.ListGradesByCourseName(courseNames)
+	loadSession()
+
+	grades, err := c.Grades(cmd.Context(), gradeFilter(cmd))
+	checkErr(err)
+
+	checkErr(util.OutputGrades(os.Stdout, grades, output))
+
+	for _, grade := range grades {
+		if statuses[grade.FinalStatus] {
+			os.Exit(util.ExitCheckFailed)
+		}
 	}
+}
+
+// gradeFilter builds the course filter from the --courseid and --course flags
+func gradeFilter(cmd *cobra.Command) util.GradeFilter {
+	courseIds, err := cmd.Flags().GetStringArray("courseid")
+	checkErr(err)
+
+	courseNames, err := cmd.Flags().GetStringArray("course")
+	checkErr(err)
 
-	if len(courseIds) == 0 && len(courseNames) == 0 {
-		c.ListGrades()
+	filter := util.GradeFilter{CourseNames: courseNames}
+	for _, id := range courseIds {
+		courseId, err := strconv.Atoi(id)
+		if err != nil {
+			checkErr(util.Errorf(util.KindUsage, "invalid course ID %q", id))
+		}
+		filter.CourseIDs = append(filter.CourseIDs, courseId)
 	}
+
+	return filter
 }
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/patitolabs/suvctl/util"
+	"github.com/spf13/cobra"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show when each grade was published or changed",
+	Long: `Show the timeline of each field of every course, from the snapshots of the grades
+kept every time they are fetched: the first value seen for a field and every later
+change, with when it was seen.
+
+Snapshots are kept in $XDG_DATA_HOME/suvctl/history.db (or the history_file setting)
+for each profile. Set history to false in the config file to stop keeping them.
+--snapshots lists the snapshots themselves, with the IDs grades diff takes.`,
+	Example: `  suvctl grades history
+  suvctl grades history --courseid 3401
+  suvctl grades history --snapshots`,
+	Args: cobra.NoArgs,
+	Run:  history,
+}
+
+func init() {
+	gradesCmd.AddCommand(historyCmd)
+
+	historyCmd.Flags().Bool("snapshots", false, "list the snapshots of the history instead")
+}
+
+func history(cmd *cobra.Command, args []string) {
+	if listSnapshots, _ := cmd.Flags().GetBool("snapshots"); listSnapshots {
+		snapshots, err := c.Snapshots()
+		checkErr(err)
+		checkErr(util.OutputSnapshots(os.Stdout, snapshots, output))
+		return
+	}
+
+	entries, err := c.GradeHistory(gradeFilter(cmd))
+	checkErr(err)
+
+	checkErr(util.OutputHistory(os.Stdout, entries, output))
+}
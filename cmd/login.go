@@ -1,8 +1,11 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
 	"os"
 
+	"github.com/patitolabs/suvctl/util"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -10,16 +13,40 @@ import (
 var loginCmd = &cobra.Command{
 	Use:   "login",
 	Short: "Create a session for the user and store it for further use",
-	Run:   login,
+	Long: `Create a session for the user and store it for further use.
+
+The user code is taken from the first of these that is available:
+  --usercode                the command line
+  SUVCTL_USERCODE           the environment
+  usercode                  the profile in the config file
+  the credential store      remembered with --remember
+  a prompt                  asked on the terminal
+
+The password is taken from the first of these that is available:
+  --password-stdin          the standard input, for CI pipelines
+  --password                the command line (visible in shell history and ps)
+  password                  the config file (kept in plaintext)
+  SUVCTL_PASSWORD           the environment
+  password_command          the output of a command set in config.yml, e.g. "pass show suv"
+  the credential store      remembered with --remember
+  a prompt                  asked on the terminal without echoing it
+
+With --remember, the user code and password are kept in the credential store
+(keyring or file, not plaintext), so that an expired session is renewed without
+asking for them. A remembered password that SUV rejects is forgotten, and the
+next login asks for it again.`,
+	Run: login,
 }
 
 func init() {
 	rootCmd.AddCommand(loginCmd)
 
 	loginCmd.Flags().StringP("usercode", "u", "", "user for SUV operations")
-	loginCmd.Flags().StringP("password", "p", "", "password for SUV operations")
+	loginCmd.Flags().StringP("password", "p", "", "password for SUV operations (prefer --password-stdin or the prompt)")
+	loginCmd.Flags().Bool("password-stdin", false, "read the password from stdin")
+	loginCmd.Flags().Bool("remember", false, "keep the credentials in the credential store to log in again when the session expires (not with the plaintext store)")
 
-	loginCmd.MarkFlagsRequiredTogether("usercode", "password")
+	loginCmd.MarkFlagsMutuallyExclusive("password", "password-stdin")
 
 	viper.BindPFlag("usercode", loginCmd.Flags().Lookup("usercode"))
 	viper.BindPFlag("password", loginCmd.Flags().Lookup("password"))
@@ -29,18 +56,39 @@ func login(cmd *cobra.Command, args []string) {
 	usercode := viper.GetString("usercode")
 	password := viper.GetString("password")
 
-	if usercode == "" || password == "" {
-		usercode = cmd.Flag("usercode").Value.String()
-		password = cmd.Flag("password").Value.String()
+	if passwordStdin, _ := cmd.Flags().GetBool("password-stdin"); passwordStdin {
+		var err error
+		password, err = util.ReadPasswordStdin(os.Stdin)
+		checkErr(err)
+	}
+
+	remember, _ := cmd.Flags().GetBool("remember")
+	if remember {
+		checkErr(util.CanRememberCredentials(c.Credentials))
 	}
 
-	if usercode == "" || password == "" {
+	usercode, password, err := c.LoginCredentials(usercode, password)
+	if errors.Is(err, util.ErrNoCredentials) {
 		cmd.Println("You must provide a user code and password")
 		cmd.Println()
 		cmd.Usage()
-		os.Exit(1)
+		os.Exit(util.ExitUsage)
 		return
 	}
+	checkErr(err)
 
-	c.Login(usercode, password)
+	migrateSessions()
+
+	session, err := c.Login(cmd.Context(), usercode, password)
+	checkErr(err)
+
+	if remember {
+		checkErr(c.RememberCredentials(usercode, password))
+	}
+
+	if viper.GetBool("detailed") {
+		fmt.Println("Login successful. Session ID:", session)
+	} else {
+		fmt.Println("Login successful")
+	}
 }
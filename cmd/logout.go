@@ -4,13 +4,14 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/patitolabs/suvctl/util"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var logoutCmd = &cobra.Command{
 	Use:   "logout",
-	Short: "Destroy a session for the user and remove it from the config file",
+	Short: "Destroy a session for the user and remove it from the credential store",
 	Run:   logout,
 }
 
@@ -23,13 +24,16 @@ func init() {
 }
 
 func logout(cmd *cobra.Command, args []string) {
+	loadSession()
+
 	if session == "" {
 		cmd.Println("No session to logout")
 		fmt.Println()
 		cmd.Usage()
-		os.Exit(1)
+		os.Exit(util.ExitAuth)
 		return
 	}
 
-	c.Logout()
+	checkErr(c.Logout(cmd.Context(), viper.GetBool("force")))
+	fmt.Println("Logout successful")
 }
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/patitolabs/suvctl/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	profileCmd = &cobra.Command{
+		Use:   "profile",
+		Short: "Manage the profiles used to switch between SUV accounts and hosts",
+	}
+
+	profileListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List the available profiles",
+		Args:  cobra.NoArgs,
+		Run:   profileList,
+	}
+
+	profileAddCmd = &cobra.Command{
+		Use:   "add NAME",
+		Short: "Add a new profile",
+		Args:  cobra.ExactArgs(1),
+		Run:   profileAdd,
+	}
+
+	profileUseCmd = &cobra.Command{
+		Use:   "use NAME",
+		Short: "Make a profile the active one",
+		Args:  cobra.ExactArgs(1),
+		Run:   profileUse,
+	}
+
+	profileRemoveCmd = &cobra.Command{
+		Use:   "remove NAME",
+		Short: "Remove a profile and its stored session",
+		Args:  cobra.ExactArgs(1),
+		Run:   profileRemove,
+	}
+
+	profileShowCmd = &cobra.Command{
+		Use:   "show [NAME]",
+		Short: "Show the settings of a profile (default is the active profile)",
+		Args:  cobra.MaximumNArgs(1),
+		Run:   profileShow,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(profileCmd)
+
+	profileCmd.AddCommand(profileListCmd)
+	profileCmd.AddCommand(profileAddCmd)
+	profileCmd.AddCommand(profileUseCmd)
+	profileCmd.AddCommand(profileRemoveCmd)
+	profileCmd.AddCommand(profileShowCmd)
+
+	profileAddCmd.Flags().StringP("usercode", "u", "", "user for SUV operations")
+	profileAddCmd.Flags().Bool("use", false, "make the new profile the active one")
+}
+
+func profileList(cmd *cobra.Command, args []string) {
+	profiles, err := util.ListProfiles()
+	checkErr(err)
+
+	active := util.ActiveProfileName()
+	for _, profile := range profiles {
+		if profile.Name == active {
+			fmt.Fprintln(cmd.OutOrStdout(), "*", profile.Name)
+		} else {
+			fmt.Fprintln(cmd.OutOrStdout(), " ", profile.Name)
+		}
+	}
+}
+
+func profileAdd(cmd *cobra.Command, args []string) {
+	profile := util.Profile{Name: args[0]}
+
+	// --host, --path and --output are global flags, only take them when given explicitly
+	if cmd.Flags().Changed("host") {
+		profile.Host, _ = cmd.Flags().GetString("host")
+	}
+	if cmd.Flags().Changed("path") {
+		profile.Path, _ = cmd.Flags().GetString("path")
+	}
+	if cmd.Flags().Changed("output") {
+		profile.Output, _ = cmd.Flags().GetString("output")
+	}
+	profile.UserCode, _ = cmd.Flags().GetString("usercode")
+
+	checkErr(util.AddProfile(profile))
+	fmt.Fprintln(cmd.OutOrStdout(), "Profile", profile.Name, "added")
+
+	if use, _ := cmd.Flags().GetBool("use"); use {
+		checkErr(util.UseProfile(profile.Name))
+		fmt.Fprintln(cmd.OutOrStdout(), "Using profile", profile.Name)
+	}
+}
+
+func profileUse(cmd *cobra.Command, args []string) {
+	checkErr(util.UseProfile(args[0]))
+	fmt.Fprintln(cmd.OutOrStdout(), "Using profile", args[0])
+}
+
+func profileRemove(cmd *cobra.Command, args []string) {
+	checkErr(util.RemoveProfile(args[0], c.Credentials))
+	fmt.Fprintln(cmd.OutOrStdout(), "Profile", args[0], "removed")
+}
+
+func profileShow(cmd *cobra.Command, args []string) {
+	name := util.ActiveProfileName()
+	if len(args) > 0 {
+		name = args[0]
+	}
+
+	profile, err := util.GetProfile(name)
+	checkErr(err)
+
+	_, err = c.Credentials.Get(profile.CredentialKey(util.SessionKey))
+	if err != nil && !errors.Is(err, util.ErrCredentialNotFound) {
+		checkErr(err)
+	}
+
+	fmt.Fprintln(cmd.OutOrStdout(), "Name:", profile.Name)
+	fmt.Fprintln(cmd.OutOrStdout(), "Active:", profile.Name == util.ActiveProfileName())
+	fmt.Fprintln(cmd.OutOrStdout(), "Host:", profile.Host)
+	fmt.Fprintln(cmd.OutOrStdout(), "Path:", profile.Path)
+	fmt.Fprintln(cmd.OutOrStdout(), "User code:", profile.UserCode)
+	fmt.Fprintln(cmd.OutOrStdout(), "Output:", profile.Output)
+	fmt.Fprintln(cmd.OutOrStdout(), "Session stored:", err == nil)
+}
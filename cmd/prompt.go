@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/patitolabs/suvctl/util"
+	"golang.org/x/term"
+)
+
+// promptTerminal asks for a value on the terminal, without echoing it back when secret.
+// It returns util.ErrNoTerminal when stdin is not a terminal.
+func promptTerminal(label string, secret bool) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", util.ErrNoTerminal
+	}
+
+	fmt.Fprint(os.Stderr, label)
+	if secret {
+		value, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", err
+		}
+		return string(value), nil
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
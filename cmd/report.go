@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/patitolabs/suvctl/util"
+	"github.com/spf13/cobra"
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Write a printable report of the grades of the current period",
+	Long: `Write a printable one page report of the grades of the current period, with the
+student, the grades of each unit and the status of every course. Lists of courses
+too long for the page are refused, narrow them down with --courseid or --course.`,
+	Example: "  suvctl grades report --pdf --out grades.pdf",
+	Args:    cobra.NoArgs,
+	Run:     report,
+}
+
+func init() {
+	gradesCmd.AddCommand(reportCmd)
+
+	reportCmd.Flags().Bool("pdf", false, "write the report as PDF")
+	reportCmd.Flags().String("out", "", "file to write the report to")
+	reportCmd.MarkFlagRequired("pdf")
+	reportCmd.MarkFlagRequired("out")
+}
+
+func report(cmd *cobra.Command, args []string) {
+	if pdf, _ := cmd.Flags().GetBool("pdf"); !pdf {
+		checkErr(util.Errorf(util.KindUsage, "only PDF reports are supported, use --pdf"))
+	}
+	out, _ := cmd.Flags().GetString("out")
+
+	loadSession()
+
+	gradeReport, err := c.GradeReport(cmd.Context(), gradeFilter(cmd))
+	checkErr(err)
+
+	file, err := os.Create(out)
+	checkErr(err)
+
+	err = gradeReport.WritePDF(file, output.Theme)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(out)
+		checkErr(err)
+	}
+
+	cmd.Println("Report written to", out)
+}
@@ -1,8 +1,12 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
 	"path"
+	"syscall"
 
 	"github.com/adrg/xdg"
 	"github.com/patitolabs/gosuv2"
@@ -20,19 +24,60 @@ var (
 		Long: `suvctl is a command-line tool for interacting with SUV2 at National University of Trujillo.
 
 Output Formats:
-  --output text     Standard text output with colors
-  --output table    Fancy ASCII table format (default)
-  --output json     Pretty JSON format
-  --output raw      Raw JSON format for piping`,
+  --output text      Standard text output with colors
+  --output table     Fancy ASCII table format (default)
+  --output json      Pretty JSON format
+  --output raw       Raw JSON format for piping
+  --output yaml      YAML with the same field names as JSON
+  --output ndjson    Newline delimited JSON, one record per line
+  --output markdown  GitHub flavored Markdown table
+  --output html      Self-contained HTML page with a colored table
+  --output csv       Comma separated values, see --delimiter and --no-header
+  --output tsv       Tab separated values
+  --output template=TEXT, --output template-file=PATH
+                     Go text/template run over the list of results
+
+--fields and --jsonpath narrow every format but text and template down to the
+fields scripts need.
+
+Colors are only used when writing to a terminal and NO_COLOR is not set, unless
+--color=always asks for them.
+
+Tables fit the width of the terminal and can be drawn with --table-border
+rounded (default), ascii, none or markdown.
+
+Exit Codes:
+  0    Success
+  1    A check did not pass: grades --fail-on matched or grades diff found changes
+  2    An error not covered below, such as failing to write a file
+  3    Bad flag, argument or setting
+  4    No course, profile or snapshot found
+  5    The session or credentials were rejected or are missing
+  6    SUV could not be reached
+  7    SUV answered with an error or something unexpected
+  130  Interrupted`,
 	}
 
 	c       *util.Client
 	config  *gosuv2.SuvConfig
 	session string
+
+	// output tells commands how to print their results
+	output util.OutputOptions
+
+	// newBackend creates the backend of the client, tests replace it to talk to a stand-in SUV
+	newBackend = func(config *gosuv2.SuvConfig) util.Backend {
+		return util.NewSuvBackend(config, nil)
+	}
 )
 
+// Execute runs suvctl. Commands exit on their own on errors, so the ones returned are
+// those found by cobra before running them, such as unknown flags.
 func Execute() error {
-	return rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return util.NewError(util.KindUsage, rootCmd.ExecuteContext(ctx))
 }
 
 func init() {
@@ -41,15 +86,32 @@ func init() {
 	rootCmd.PersistentFlags().StringP("host", "H", "", "SUV host FQDN (default is suv2.unitru.edu.pe)")
 	rootCmd.PersistentFlags().StringP("path", "P", "", "SUV path (default is empty)")
 	rootCmd.PersistentFlags().StringP("session", "S", "", "session for SUV operations")
+	rootCmd.PersistentFlags().String("profile", "", "profile to use (default is the active profile)")
 	rootCmd.PersistentFlags().BoolP("detailed", "d", false, "show detailed information")
 	rootCmd.PersistentFlags().BoolP("version", "v", false, "show version information")
-	rootCmd.PersistentFlags().StringP("output", "o", "table", "output format (text, table, json, raw)")
-
-	viper.BindPFlag("url", rootCmd.PersistentFlags().Lookup("url"))
+	rootCmd.PersistentFlags().StringP("output", "o", "table", "output format (text, table, json, raw, yaml, ndjson, markdown, html, csv, tsv, template=TEXT, template-file=PATH)")
+	rootCmd.PersistentFlags().StringSlice("fields", nil, "comma separated fields to show, named as in json output")
+	rootCmd.PersistentFlags().String("jsonpath", "", "JSONPath expression selecting what to show, such as {.[*].course_name}")
+	rootCmd.PersistentFlags().String("delimiter", "", "field delimiter of csv output (default is ',')")
+	rootCmd.PersistentFlags().Bool("no-header", false, "leave out the header of csv and tsv output")
+	rootCmd.PersistentFlags().String("color", "auto", "when to color output (auto, always, never)")
+	rootCmd.PersistentFlags().String("table-border", "", "border style of table output (rounded, ascii, none, markdown) (default is rounded)")
+	rootCmd.PersistentFlags().String("credential-store", "", "where to keep the session (keyring, file, plaintext) (default is keyring)")
+
+	viper.BindPFlag("host", rootCmd.PersistentFlags().Lookup("host"))
+	viper.BindPFlag("path", rootCmd.PersistentFlags().Lookup("path"))
+	viper.BindPFlag("profile", rootCmd.PersistentFlags().Lookup("profile"))
 	viper.BindPFlag("session", rootCmd.PersistentFlags().Lookup("session"))
 	viper.BindPFlag("detailed", rootCmd.PersistentFlags().Lookup("detailed"))
 	viper.BindPFlag("version", rootCmd.PersistentFlags().Lookup("version"))
 	viper.BindPFlag("output", rootCmd.PersistentFlags().Lookup("output"))
+	viper.BindPFlag("fields", rootCmd.PersistentFlags().Lookup("fields"))
+	viper.BindPFlag("jsonpath", rootCmd.PersistentFlags().Lookup("jsonpath"))
+	viper.BindPFlag("delimiter", rootCmd.PersistentFlags().Lookup("delimiter"))
+	viper.BindPFlag("no_header", rootCmd.PersistentFlags().Lookup("no-header"))
+	viper.BindPFlag("color", rootCmd.PersistentFlags().Lookup("color"))
+	viper.BindPFlag("table_border", rootCmd.PersistentFlags().Lookup("table-border"))
+	viper.BindPFlag("credential_store", rootCmd.PersistentFlags().Lookup("credential-store"))
 }
 
 func initConfig() {
@@ -87,17 +149,95 @@ func initConfig() {
 		fmt.Println()
 	}
 
-	if viper.GetString("session") != "" {
-		session = viper.GetString("session")
-	} else {
-		session = rootCmd.Flags().Lookup("session").Value.String()
+	rules, err := util.GetStatusRules()
+	checkErr(err)
+
+	backend, err := util.GetCredentialBackend()
+	checkErr(err)
+
+	store, err := util.NewCredentialStore(backend, util.CredentialOptions{
+		File:   viper.GetString("credential_file"),
+		Prompt: promptTerminal,
+	})
+	checkErr(err)
+
+	profile, err := util.ActiveProfile()
+	checkErr(err)
+
+	util.ApplyProfile(profile, rootCmd.PersistentFlags(), loginCmd.Flags())
+
+	if viper.GetBool("detailed") {
+		fmt.Println("Using profile:", profile.Name)
+		fmt.Println()
 	}
 
+	// The profile may choose the output format, read the options once it is applied
+	output, err = util.GetOutputOptions()
+	checkErr(err)
+
 	config = util.ReadConfig()
-	c = util.NewClient(config)
+	c = util.NewClient(newBackend(config), store, profile)
+	c.StatusRules = rules
+	c.PasswordCommand = viper.GetString("password_command")
+	c.Prompt = promptTerminal
+	c.OnRelogin = func(session string) {
+		if viper.GetBool("detailed") {
+			fmt.Fprintln(os.Stderr, "Session expired, logged in again. Session ID:", session)
+			fmt.Fprintln(os.Stderr)
+		}
+	}
+
+	viper.SetDefault("history", true)
+	viper.SetDefault("history_limit", 1000)
+	if viper.GetBool("history") {
+		c.History = util.NewHistory(util.HistoryFilePath(), viper.GetInt("history_limit"))
+		c.OnHistoryError = func(err error) {
+			fmt.Fprintln(os.Stderr, "Warning: the grades were not added to the history:", err)
+		}
+	}
+}
+
+// checkErr prints err and exits with the exit code of its kind, where cobra.CheckErr
+// would exit with 1 for every error
+func checkErr(err error) {
+	if err == nil {
+		return
+	}
+	fmt.Fprintln(os.Stderr, "Error:", err)
+	os.Exit(util.ExitCode(err))
+}
+
+// migrateSessions moves the sessions left in the config file into the credential store.
+// Commands work without it, so failing to do it is only a warning.
+func migrateSessions() {
+	migrated, err := util.MigratePlaintextSessions(c.Credentials)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Warning: the sessions in the config file were not moved to the credential store:", err)
+		return
+	}
+
+	if migrated > 0 && viper.GetBool("detailed") {
+		fmt.Println("Moved", migrated, "session(s) from the config file to the credential store")
+		fmt.Println()
+	}
+}
+
+// loadSession resolves the session from the --session flag or the credential store entry
+// of the active profile and loads it into the client. It is only called by commands that
+// need a session, so that the credential store is not unlocked for every invocation.
+func loadSession() {
+	migrateSessions()
+
+	if rootCmd.PersistentFlags().Changed("session") {
+		session = rootCmd.PersistentFlags().Lookup("session").Value.String()
+	} else {
+		stored, err := c.StoredPhpSession()
+		checkErr(err)
+		session = stored
+	}
 
 	if session != "" {
-		c.SetPhpSession(session)
+		checkErr(c.LoadPhpSession(session))
 
 		if viper.GetBool("detailed") {
 			fmt.Println("Using session:", session)
@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"os"
+
+	"github.com/patitolabs/suvctl/util"
 	"github.com/spf13/cobra"
 )
 
@@ -25,6 +28,8 @@ func init() {
 }
 
 func search(cmd *cobra.Command, args []string) {
+	loadSession()
+
 	professors := cmd.Flag("professors").Value.String() == "true"
 	code := cmd.Flag("code").Value.String()
 	name := cmd.Flag("name").Value.String()
@@ -35,12 +40,17 @@ func search(cmd *cobra.Command, args []string) {
 		cmd.Println("You must provide a code, name or dni")
 		cmd.Println()
 		cmd.Usage()
-		return
+		os.Exit(util.ExitUsage)
 	}
 
 	if professors {
-		c.SearchProfessor(name, lastname)
+		found, err := c.SearchProfessors(cmd.Context(), name, lastname)
+		checkErr(err)
+		checkErr(util.OutputProfessors(os.Stdout, found, output))
 	} else {
-		c.SearchStudent(code, name, lastname, dni)
+		query := util.StudentQuery{Code: code, Name: name, Lastname: lastname, DNI: dni}
+		found, err := c.SearchStudents(cmd.Context(), query)
+		checkErr(err)
+		checkErr(util.OutputStudents(os.Stdout, found, output))
 	}
 }
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/patitolabs/suvctl/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	sessionCmd = &cobra.Command{
+		Use:   "session",
+		Short: "Inspect the session of the active profile",
+	}
+
+	sessionStatusCmd = &cobra.Command{
+		Use:   "status",
+		Short: "Check whether the stored session is still accepted by SUV",
+		Long: `Check whether the stored session is still accepted by SUV.
+
+It exits with 0 when the session is alive and with 5, as for any rejected or
+missing session, when it has expired or none is stored.`,
+		Args: cobra.NoArgs,
+		Run:  sessionStatus,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(sessionCmd)
+
+	sessionCmd.AddCommand(sessionStatusCmd)
+}
+
+func sessionStatus(cmd *cobra.Command, args []string) {
+	loadSession()
+
+	if session == "" {
+		fmt.Fprintln(cmd.OutOrStdout(), "No session stored for profile", c.Profile.Name)
+		os.Exit(util.ExitAuth)
+		return
+	}
+
+	alive, err := c.SessionStatus(cmd.Context())
+	checkErr(err)
+
+	if !alive {
+		fmt.Fprintln(cmd.OutOrStdout(), "The session of profile", c.Profile.Name, "has expired")
+		os.Exit(util.ExitAuth)
+		return
+	}
+
+	fmt.Fprintln(cmd.OutOrStdout(), "The session of profile", c.Profile.Name, "is alive")
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/patitolabs/suvctl/util"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var summaryCmd = &cobra.Command{
+	Use:   "summary",
+	Short: "Sum up the current period",
+	Long: `Sum up the current period: the courses passed, failed, pending and disqualified,
+the average of the final grades weighted by the credits of each course, and the
+courses at risk with what they still need.
+
+Credits are read from the course catalog, a YAML or JSON list of course_id and
+credits ($HOME/.config/suvctl/catalog.yml by default), and from the credits
+section of the config file, which maps course IDs to credits.`,
+	Example: `  suvctl grades summary
+  suvctl grades summary --catalog catalog.yml -o json`,
+	Args: cobra.NoArgs,
+	Run:  summary,
+}
+
+func init() {
+	gradesCmd.AddCommand(summaryCmd)
+
+	summaryCmd.Flags().String("catalog", "", "course catalog file with the credits of each course")
+
+	viper.BindPFlag("catalog", summaryCmd.Flags().Lookup("catalog"))
+}
+
+func summary(cmd *cobra.Command, args []string) {
+	catalog, err := util.LoadCatalog()
+	checkErr(err)
+	c.Catalog = catalog
+
+	loadSession()
+
+	summary, err := c.Summary(cmd.Context(), gradeFilter(cmd))
+	checkErr(err)
+
+	checkErr(util.OutputSummary(os.Stdout, *summary, output))
+}
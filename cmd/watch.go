@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/patitolabs/suvctl/util"
+	"github.com/spf13/cobra"
+)
+
+// minWatchInterval keeps watches from polling SUV more often than it can take
+const minWatchInterval = time.Minute
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Poll SUV and report new or changed grades",
+	Long: `Fetch the grades every --interval and print what was added, changed or removed
+since the previous fetch, until interrupted with Ctrl+C or SIGTERM.
+
+Failed fetches are retried sooner than --interval at first and then less and less
+often, up to --max-backoff. An expired session is renewed with the remembered
+credentials, as in every other command; when that is not possible, the watch stops.
+
+Changes are also sent to the notifiers in the notify section of the config file,
+unless --notify=false.`,
+	Example: `  suvctl grades watch
+  suvctl grades watch --interval 10m --courseid 3403
+  suvctl grades watch -o ndjson >> changes.ndjson`,
+	Args: cobra.NoArgs,
+	Run:  watch,
+}
+
+func init() {
+	gradesCmd.AddCommand(watchCmd)
+
+	watchCmd.Flags().Duration("interval", 15*time.Minute, "time between fetches")
+	watchCmd.Flags().Duration("max-backoff", time.Hour, "longest time between retries while fetching fails")
+	watchCmd.Flags().Bool("notify", true, "send the changes to the notifiers in the config file")
+}
+
+func watch(cmd *cobra.Command, args []string) {
+	interval, _ := cmd.Flags().GetDuration("interval")
+	maxBackoff, _ := cmd.Flags().GetDuration("max-backoff")
+	notify, _ := cmd.Flags().GetBool("notify")
+	if interval < minWatchInterval {
+		checkErr(util.Errorf(util.KindUsage, "the interval must be at least %s", minWatchInterval))
+	}
+
+	// Check the notify section now rather than when the first change comes
+	var notifiers util.Notifiers
+	if notify {
+		var err error
+		notifiers, err = util.GetNotifiers()
+		checkErr(err)
+	}
+
+	loadSession()
+
+	fmt.Fprintf(os.Stderr, "Watching the grades every %s, press Ctrl+C to stop\n", interval)
+
+	err := c.Watch(cmd.Context(), util.WatchOptions{
+		Interval:   interval,
+		MaxBackoff: maxBackoff,
+		Filter:     gradeFilter(cmd),
+		OnChanges: func(changes []util.GradeChange) error {
+			if output.Format == util.OutputText || output.Format == util.OutputTable {
+				fmt.Println(time.Now().Format("2006-01-02 15:04"))
+			}
+			if err := util.OutputChanges(os.Stdout, changes, output); err != nil {
+				return err
+			}
+			notifyChanges(cmd.Context(), notifiers, changes)
+			return nil
+		},
+		OnError: func(err error, retry time.Duration) {
+			fmt.Fprintf(os.Stderr, "Warning: fetching the grades failed, retrying in %s: %v\n", retry.Round(time.Second), err)
+		},
+	})
+	checkErr(err)
+
+	fmt.Fprintln(os.Stderr, "Stopped watching the grades")
+}
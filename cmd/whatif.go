@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/patitolabs/suvctl/util"
+	"github.com/spf13/cobra"
+)
+
+var whatifCmd = &cobra.Command{
+	Use:   "whatif",
+	Short: "Show the grades needed to pass each course",
+	Long: `Show the lowest grade needed in the remaining units of each course to reach the
+passing grade, and on the substitute exam, which replaces the lowest graded unit,
+along with the remaining units.
+
+Units are weighted with --weights, or with the weights from SUV, or with the weights in
+the grading section of the config file, or equally, in that order.`,
+	Example: `  suvctl grades whatif
+  suvctl grades whatif -i 3403 --weights 0.3,0.3,0.4`,
+	Args: cobra.NoArgs,
+	Run:  whatif,
+}
+
+func init() {
+	gradesCmd.AddCommand(whatifCmd)
+
+	whatifCmd.Flags().Float32Slice("weights", nil, "weights of the units, as in 0.3,0.3,0.4")
+}
+
+func whatif(cmd *cobra.Command, args []string) {
+	weights, err := cmd.Flags().GetFloat32Slice("weights")
+	checkErr(err)
+
+	loadSession()
+
+	projections, err := c.WhatIf(cmd.Context(), gradeFilter(cmd), weights)
+	checkErr(err)
+
+	checkErr(util.OutputProjections(os.Stdout, projections, output))
+}
@@ -1,7 +1,14 @@
 package main
 
-import "github.com/patitolabs/suvctl/cmd"
+import (
+	"os"
+
+	"github.com/patitolabs/suvctl/cmd"
+	"github.com/patitolabs/suvctl/util"
+)
 
 func main() {
-	cmd.Execute()
+	if err := cmd.Execute(); err != nil {
+		os.Exit(util.ExitCode(err))
+	}
 }
@@ -0,0 +1,237 @@
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/patitolabs/gosuv2"
+)
+
+// Backend is the part of the SUV2 API used by Client. Implementations report a session
+// rejected by SUV with an error wrapping ErrSessionExpired, and other failures as errors
+// of KindAuth, KindNetwork or KindServer where they can tell them apart.
+type Backend interface {
+	Login(ctx context.Context, usercode, password string) (string, error)
+	Logout(ctx context.Context) error
+	LoadPhpSession(session string) error
+	GetSuvGradesResponse(ctx context.Context) (*gosuv2.SuvGradesResponse, error)
+	SearchStudentByCode(ctx context.Context, code string) ([]gosuv2.StudentBasicResponse, error)
+	SearchStudentByName(ctx context.Context, name, lastname string) ([]gosuv2.StudentBasicResponse, error)
+	SearchStudentByDni(ctx context.Context, dni string) ([]gosuv2.StudentBasicResponse, error)
+	SearchProfessor(ctx context.Context, name, lastname string) ([]gosuv2.ProfessorBasicResponse, error)
+}
+
+// errUnexpectedSearchResponse is reported when SUV does not answer a search with a list,
+// which gosuv2 does not check before asserting the type of the result
+var errUnexpectedSearchResponse = errors.New("unexpected search response")
+
+// SuvBackend is the Backend talking to SUV2 through gosuv2
+type SuvBackend struct {
+	client    *gosuv2.SuvClient
+	transport *contextTransport
+}
+
+// NewSuvBackend creates a backend for the SUV2 instance in config. Requests go through
+// transport, or http.DefaultTransport when it is nil.
+func NewSuvBackend(config *gosuv2.SuvConfig, transport http.RoundTripper) *SuvBackend {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	backend := &SuvBackend{
+		client:    gosuv2.NewSuvClient(*config),
+		transport: &contextTransport{base: transport},
+	}
+	backend.client.HttpClient.Transport = backend.transport
+
+	return backend
+}
+
+func (b *SuvBackend) Login(ctx context.Context, usercode, password string) (string, error) {
+	var session string
+
+	err := b.do(ctx, func() error {
+		phpSession, err := b.client.Login(usercode, password)
+		if err != nil {
+			return err
+		}
+		session = *phpSession
+		return nil
+	})
+
+	return session, err
+}
+
+func (b *SuvBackend) Logout(ctx context.Context) error {
+	return b.do(ctx, b.client.Logout)
+}
+
+func (b *SuvBackend) LoadPhpSession(session string) error {
+	b.client.Config.PhpSession = session
+	return b.client.LoadPhpSession()
+}
+
+func (b *SuvBackend) GetSuvGradesResponse(ctx context.Context) (*gosuv2.SuvGradesResponse, error) {
+	var suvGradesResponse *gosuv2.SuvGradesResponse
+
+	err := b.do(ctx, func() error {
+		var err error
+		suvGradesResponse, err = b.client.GetSuvGradesResponse()
+		return b.sessionError(err)
+	})
+
+	return suvGradesResponse, err
+}
+
+func (b *SuvBackend) SearchStudentByCode(ctx context.Context, code string) ([]gosuv2.StudentBasicResponse, error) {
+	return searchWith(b, ctx, func() (*[]gosuv2.StudentBasicResponse, error) {
+		return b.client.SearchStudentByCode(code)
+	})
+}
+
+func (b *SuvBackend) SearchStudentByName(ctx context.Context, name, lastname string) ([]gosuv2.StudentBasicResponse, error) {
+	return searchWith(b, ctx, func() (*[]gosuv2.StudentBasicResponse, error) {
+		return b.client.SearchStudentByName(name, lastname)
+	})
+}
+
+func (b *SuvBackend) SearchStudentByDni(ctx context.Context, dni string) ([]gosuv2.StudentBasicResponse, error) {
+	return searchWith(b, ctx, func() (*[]gosuv2.StudentBasicResponse, error) {
+		return b.client.SearchStudentByDni(dni)
+	})
+}
+
+func (b *SuvBackend) SearchProfessor(ctx context.Context, name, lastname string) ([]gosuv2.ProfessorBasicResponse, error) {
+	return searchWith(b, ctx, func() (*[]gosuv2.ProfessorBasicResponse, error) {
+		return b.client.SearchProfessor(name, lastname)
+	})
+}
+
+// do runs request, which calls gosuv2, so that its HTTP requests honor ctx
+func (b *SuvBackend) do(ctx context.Context, request func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	b.transport.ctx = ctx
+	b.transport.err = nil
+	b.transport.loginPage = false
+	defer func() { b.transport.ctx = nil }()
+
+	return backendError(request())
+}
+
+// errLoginFailed is what gosuv2 answers a login with when SUV rejects the credentials
+const errLoginFailed = "login failed, check your credentials"
+
+// backendError gives the errors of gosuv2 their kind: transport failures are network
+// errors, a rejected login is an auth one, and the rest come from what SUV answered
+func backendError(err error) error {
+	var urlErr *url.Error
+	var kindErr *Error
+
+	switch {
+	case err == nil || errors.As(err, &kindErr) || errors.Is(err, context.Canceled):
+		return err
+	case errors.As(err, &urlErr) || errors.Is(err, context.DeadlineExceeded):
+		return NewError(KindNetwork, err)
+	case err.Error() == errLoginFailed:
+		return NewError(KindAuth, err)
+	default:
+		return NewError(KindServer, err)
+	}
+}
+
+// searchWith runs a gosuv2 search, turning the panic it raises when SUV does not answer
+// with a list into the transport error behind it or a session error
+func searchWith[T any](b *SuvBackend, ctx context.Context, search func() (*[]T, error)) ([]T, error) {
+	var results []T
+
+	err := b.do(ctx, func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				if b.transport.err != nil {
+					err = b.transport.err
+				} else {
+					err = b.sessionError(errUnexpectedSearchResponse)
+				}
+			}
+		}()
+
+		found, err := search()
+		if err != nil {
+			return b.sessionError(err)
+		}
+		results = *found
+		return nil
+	})
+
+	return results, err
+}
+
+// sessionError marks err as caused by SUV rejecting the session when SUV answered with
+// its login page where JSON was expected, and returns it as is otherwise
+func (b *SuvBackend) sessionError(err error) error {
+	if err == nil || !b.transport.loginPage {
+		return err
+	}
+	return fmt.Errorf("%w: %v", ErrSessionExpired, err)
+}
+
+// contextTransport attaches the context of the call in progress to the HTTP requests
+// made by gosuv2, which has no context support of its own. It also keeps the last
+// transport error, which gosuv2 discards on searches, and whether the last response was
+// the login page, as SUV answers with it when it rejects the session.
+type contextTransport struct {
+	base      http.RoundTripper
+	ctx       context.Context
+	err       error
+	loginPage bool
+}
+
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.ctx != nil {
+		req = req.WithContext(t.ctx)
+	}
+
+	res, err := t.base.RoundTrip(req)
+	if err != nil {
+		t.err = &url.Error{Op: req.Method, URL: req.URL.String(), Err: err}
+		return res, err
+	}
+
+	body := bufio.NewReaderSize(res.Body, loginPageMaxSize)
+	t.loginPage = isLoginPage(res, body)
+	res.Body = struct {
+		io.Reader
+		io.Closer
+	}{body, res.Body}
+
+	return res, nil
+}
+
+// loginPageMaxSize is how much of an HTML answer is searched for the login form
+const loginPageMaxSize = 64 << 10
+
+// isLoginPage tells whether res is the SUV login page, a successful HTML answer holding
+// the form that posts the user code and password to validar.php. Other HTML pages, such
+// as the error page of a proxy or a captive portal, are not.
+func isLoginPage(res *http.Response, body *bufio.Reader) bool {
+	if res.StatusCode < 200 || res.StatusCode >= 400 {
+		return false
+	}
+
+	start, _ := body.Peek(512)
+	if !bytes.HasPrefix(bytes.TrimSpace(start), []byte("<")) {
+		return false
+	}
+
+	page, _ := body.Peek(loginPageMaxSize)
+	return bytes.Contains(page, []byte("validar.php")) && bytes.Contains(page, []byte(`name="pass"`))
+}
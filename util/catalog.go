@@ -0,0 +1,74 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strconv"
+
+	"github.com/adrg/xdg"
+	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
+)
+
+// Catalog maps course IDs to their credits
+type Catalog map[int]float32
+
+// catalogEntry is a course of a catalog file
+type catalogEntry struct {
+	CourseID int     `yaml:"course_id"`
+	Credits  float32 `yaml:"credits"`
+}
+
+// CatalogFilePath returns the course catalog file from viper config, or the default one
+func CatalogFilePath() string {
+	if file := viper.GetString("catalog"); file != "" {
+		return file
+	}
+	return path.Join(xdg.ConfigHome, "suvctl", "catalog.yml")
+}
+
+// LoadCatalog returns the credits of the courses listed in the catalog file, a YAML or
+// JSON list of course_id and credits, and in the credits section of viper config, which
+// takes precedence. The default catalog file may be missing.
+func LoadCatalog() (Catalog, error) {
+	catalog := Catalog{}
+
+	file := CatalogFilePath()
+	data, err := os.ReadFile(file)
+	switch {
+	case errors.Is(err, fs.ErrNotExist) && viper.GetString("catalog") == "":
+	case err != nil:
+		return nil, fmt.Errorf("error reading course catalog: %w", err)
+	default:
+		var entries []catalogEntry
+		if err := yaml.Unmarshal(data, &entries); err != nil {
+			return nil, fmt.Errorf("error reading course catalog %s: %w", file, err)
+		}
+		for _, entry := range entries {
+			catalog[entry.CourseID] = entry.Credits
+		}
+	}
+
+	for key, value := range viper.GetStringMap("credits") {
+		id, err := strconv.Atoi(key)
+		if err != nil {
+			return nil, Errorf(KindUsage, "invalid credits: %q is not a course ID", key)
+		}
+		credits, err := strconv.ParseFloat(fmt.Sprint(value), 32)
+		if err != nil {
+			return nil, Errorf(KindUsage, "invalid credits of course %d: %v", id, value)
+		}
+		catalog[id] = float32(credits)
+	}
+
+	for id, credits := range catalog {
+		if credits <= 0 {
+			return nil, Errorf(KindUsage, "invalid credits of course %d: they must be positive", id)
+		}
+	}
+
+	return catalog, nil
+}
@@ -0,0 +1,45 @@
+package util_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/patitolabs/suvctl/util"
+	"github.com/spf13/viper"
+)
+
+func TestLoadCatalog(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "catalog.yml")
+	catalog := "- course_id: 3401\n  credits: 4\n- course_id: 3402\n  credits: 3\n"
+	if err := os.WriteFile(file, []byte(catalog), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	viper.Set("catalog", file)
+	viper.Set("credits", map[string]any{"3402": 5, "3403": 2.5})
+	t.Cleanup(func() {
+		viper.Set("catalog", "")
+		viper.Set("credits", nil)
+	})
+
+	got, err := util.LoadCatalog()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := util.Catalog{3401: 4, 3402: 5, 3403: 2.5}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for id, credits := range want {
+		if got[id] != credits {
+			t.Errorf("credits of %d: got %v, want %v", id, got[id], credits)
+		}
+	}
+
+	viper.Set("catalog", filepath.Join(t.TempDir(), "missing.yml"))
+	if _, err := util.LoadCatalog(); err == nil {
+		t.Error("expected an error for a missing catalog given explicitly")
+	}
+}
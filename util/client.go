@@ -1,35 +1,94 @@
 package util
 
 import (
+	"errors"
+
 	"github.com/patitolabs/gosuv2"
 	"github.com/spf13/viper"
 )
 
 type Client struct {
-	SuvConfig *gosuv2.SuvConfig
-	SuvClient *gosuv2.SuvClient
+	Backend     Backend
+	Credentials CredentialStore
+	Profile     *Profile
+
+	// StatusRules give the final status of the courses, and Catalog the credits Summary
+	// weights them with
+	StatusRules StatusRules
+	Catalog     Catalog
+
+	// PasswordCommand, when set, is run for the password when logging in without one.
+	// Prompt, when set, asks for the user code and password still missing then.
+	PasswordCommand string
+	Prompt          Prompter
+
+	// OnRelogin, when set, is called after the client logged in again on its own
+	// because SUV rejected the session
+	OnRelogin func(session string)
+
+	// History, when set, keeps a snapshot of the grades every time they are fetched.
+	// Failing to record one does not fail the request, OnHistoryError is called instead.
+	History        *History
+	OnHistoryError func(err error)
+
+	session string
 }
 
+// ReadConfig returns the SUV configuration from viper config, which already includes the
+// settings of the active profile once ApplyProfile has been called
 func ReadConfig() *gosuv2.SuvConfig {
 	return &gosuv2.SuvConfig{
-		Host:       viper.GetString("host"),
-		Path:       viper.GetString("path"),
-		PhpSession: viper.GetString("session"),
-		Detailed:   viper.GetBool("detailed"),
+		Host:     viper.GetString("host"),
+		Path:     viper.GetString("path"),
+		Detailed: viper.GetBool("detailed"),
 	}
 }
 
-func NewClient(config *gosuv2.SuvConfig) *Client {
+// NewClient creates a client for backend whose session is kept in credentials under profile,
+// grading with DefaultStatusRules
+func NewClient(backend Backend, credentials CredentialStore, profile *Profile) *Client {
 	return &Client{
-		SuvConfig: config,
-		SuvClient: gosuv2.NewSuvClient(*config),
+		Backend:     backend,
+		Credentials: credentials,
+		Profile:     profile,
+		StatusRules: DefaultStatusRules,
+	}
+}
+
+// LoadPhpSession makes the client use session without persisting it
+func (c *Client) LoadPhpSession(session string) error {
+	c.session = session
+
+	if session == "" {
+		return nil
 	}
+	return c.Backend.LoadPhpSession(session)
+}
+
+// StoredPhpSession returns the session kept in the credential store, if any
+func (c *Client) StoredPhpSession() (string, error) {
+	return c.storedCredential(SessionKey)
 }
 
-func (c *Client) SetPhpSession(session string) {
-	c.SuvConfig.PhpSession = session
-	c.SuvClient.LoadPhpSession()
-	viper.Set("session", &session)
-	viper.Set("detailed", false)
-	viper.WriteConfig()
+// storedCredential returns a secret of the active profile, or an empty string if it was
+// never stored
+func (c *Client) storedCredential(key string) (string, error) {
+	value, err := c.Credentials.Get(c.Profile.CredentialKey(key))
+	if errors.Is(err, ErrCredentialNotFound) {
+		return "", nil
+	}
+	return value, err
+}
+
+// SetPhpSession makes the client use session and persists it in the credential store.
+// An empty session removes the stored one.
+func (c *Client) SetPhpSession(session string) error {
+	if err := c.LoadPhpSession(session); err != nil {
+		return err
+	}
+
+	if session == "" {
+		return c.Credentials.Delete(c.Profile.CredentialKey(SessionKey))
+	}
+	return c.Credentials.Set(c.Profile.CredentialKey(SessionKey), session)
 }
@@ -0,0 +1,194 @@
+package util
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+)
+
+// Color is a foreground color: the name of one of the 16 ANSI colors, such as "red" or
+// "bright-blue", an index of the 256 color palette, such as "208", or an RGB color, such
+// as "#0072b2". "" and "default" leave text in the default color.
+type Color string
+
+const (
+	ColorDefault   Color = ""
+	ColorRed       Color = "red"
+	ColorGreen     Color = "green"
+	ColorYellow    Color = "yellow"
+	ColorLightBlue Color = "bright-blue"
+
+	colorReset = "\033[0m"
+)
+
+// ansiColors lists the 16 ANSI colors in palette order, with the RGB values used for them
+// outside the terminal, in HTML and PDF output
+var ansiColors = []struct {
+	name string
+	rgb  [3]uint8
+}{
+	{"black", [3]uint8{0x21, 0x21, 0x21}},
+	{"red", [3]uint8{0xc6, 0x28, 0x28}},
+	{"green", [3]uint8{0x2e, 0x7d, 0x32}},
+	{"yellow", [3]uint8{0xb7, 0x79, 0x1f}},
+	{"blue", [3]uint8{0x0d, 0x47, 0xa1}},
+	{"magenta", [3]uint8{0x8e, 0x24, 0xaa}},
+	{"cyan", [3]uint8{0x00, 0x83, 0x8f}},
+	{"white", [3]uint8{0x9e, 0x9e, 0x9e}},
+	{"bright-black", [3]uint8{0x61, 0x61, 0x61}},
+	{"bright-red", [3]uint8{0xe5, 0x39, 0x35}},
+	{"bright-green", [3]uint8{0x43, 0xa0, 0x47}},
+	{"bright-yellow", [3]uint8{0xf9, 0xa8, 0x25}},
+	{"bright-blue", [3]uint8{0x15, 0x65, 0xc0}},
+	{"bright-magenta", [3]uint8{0xab, 0x47, 0xbc}},
+	{"bright-cyan", [3]uint8{0x00, 0xac, 0xc1}},
+	{"bright-white", [3]uint8{0xbd, 0xbd, 0xbd}},
+}
+
+// parse returns the escape sequence selecting the color in a terminal and its RGB value.
+// ok is false for the default color.
+func (c Color) parse() (sequence string, rgb [3]uint8, ok bool, err error) {
+	name := strings.ToLower(strings.TrimSpace(string(c)))
+	name = strings.Replace(name, "light-", "bright-", 1)
+
+	if name == "" || name == "default" || name == "none" {
+		return "", rgb, false, nil
+	}
+
+	for i, color := range ansiColors {
+		if color.name == name {
+			if i < 8 {
+				return fmt.Sprintf("\033[%dm", 30+i), color.rgb, true, nil
+			}
+			return fmt.Sprintf("\033[%dm", 90+i-8), color.rgb, true, nil
+		}
+	}
+
+	if hex, found := strings.CutPrefix(name, "#"); found {
+		value, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil || len(hex) != 6 {
+			return "", rgb, false, Errorf(KindUsage, "invalid color %q: use #rrggbb", c)
+		}
+		rgb = [3]uint8{uint8(value >> 16), uint8(value >> 8), uint8(value)}
+		return fmt.Sprintf("\033[38;2;%d;%d;%dm", rgb[0], rgb[1], rgb[2]), rgb, true, nil
+	}
+
+	index, err := strconv.Atoi(name)
+	if err != nil || index < 0 || index > 255 {
+		return "", rgb, false, Errorf(KindUsage, "invalid color %q: use a color name, a number from 0 to 255 or #rrggbb", c)
+	}
+	return fmt.Sprintf("\033[38;5;%dm", index), palette256(index), true, nil
+}
+
+// Validate checks that c names a color
+func (c Color) Validate() error {
+	_, _, _, err := c.parse()
+	return err
+}
+
+// CSS returns the color as #rrggbb, or "" for the default color
+func (c Color) CSS() string {
+	rgb, ok := c.RGB()
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("#%02x%02x%02x", rgb[0], rgb[1], rgb[2])
+}
+
+// RGB returns the red, green and blue components of the color, ok is false for the
+// default color and invalid ones
+func (c Color) RGB() (rgb [3]uint8, ok bool) {
+	_, rgb, ok, err := c.parse()
+	return rgb, ok && err == nil
+}
+
+// palette256 returns the RGB value of a color of the xterm 256 color palette
+func palette256(index int) [3]uint8 {
+	switch {
+	case index < 16:
+		return ansiColors[index].rgb
+	case index < 232:
+		levels := [6]uint8{0, 95, 135, 175, 215, 255}
+		index -= 16
+		return [3]uint8{levels[index/36], levels[index/6%6], levels[index%6]}
+	default:
+		gray := uint8(8 + 10*(index-232))
+		return [3]uint8{gray, gray, gray}
+	}
+}
+
+// ColorMode tells when output is colored
+type ColorMode string
+
+const (
+	// ColorAuto colors output written to a terminal, unless NO_COLOR is set or TERM is dumb
+	ColorAuto   ColorMode = "auto"
+	ColorAlways ColorMode = "always"
+	ColorNever  ColorMode = "never"
+)
+
+// GetColorMode returns the color mode from viper config, auto by default
+func GetColorMode() (ColorMode, error) {
+	switch mode := ColorMode(viper.GetString("color")); mode {
+	case "":
+		return ColorAuto, nil
+	case ColorAuto, ColorAlways, ColorNever:
+		return mode, nil
+	default:
+		return "", Errorf(KindUsage, "unknown color mode %q (use auto, always or never)", mode)
+	}
+}
+
+// ColorEnabled reports whether output written to w should be colored in the given mode,
+// auto when empty
+func ColorEnabled(w io.Writer, mode ColorMode) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// Colorizer paints text in the colors of output that takes them, and leaves it alone
+// otherwise. Every ANSI escape sequence suvctl prints goes through it.
+type Colorizer struct {
+	enabled bool
+	theme   Theme
+}
+
+// NewColorizer returns a Colorizer painting output written to w in the given mode with
+// the colors of theme
+func NewColorizer(w io.Writer, mode ColorMode, theme Theme) Colorizer {
+	return Colorizer{enabled: ColorEnabled(w, mode), theme: theme}
+}
+
+// Enabled reports whether the colorizer paints text
+func (c Colorizer) Enabled() bool {
+	return c.enabled
+}
+
+// Paint returns text in color, or text alone when colors are disabled
+func (c Colorizer) Paint(color Color, text string) string {
+	if !c.enabled || text == "" {
+		return text
+	}
+
+	sequence, _, ok, err := color.parse()
+	if !ok || err != nil {
+		return text
+	}
+	return sequence + text + colorReset
+}
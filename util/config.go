@@ -0,0 +1,119 @@
+package util
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/adrg/xdg"
+	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
+)
+
+// ConfigFilePath returns the config file in use, or the default location when none was found
+func ConfigFilePath() string {
+	if file := viper.ConfigFileUsed(); file != "" {
+		return file
+	}
+	return path.Join(xdg.ConfigHome, "suvctl", "config.yml")
+}
+
+// readConfigFile returns the settings stored on disk, without flags, defaults or overrides
+func readConfigFile() (map[string]any, error) {
+	settings := map[string]any{}
+
+	data, err := os.ReadFile(ConfigFilePath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return settings, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(data, &settings); err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		settings = map[string]any{}
+	}
+
+	return settings, nil
+}
+
+// UpdateConfig applies update to the config file on disk and reloads it into viper.
+// Unlike viper.WriteConfig, it creates the file when missing, keeps flag values out of
+// it and lets callers remove keys.
+func UpdateConfig(update func(settings map[string]any)) error {
+	settings, err := readConfigFile()
+	if err != nil {
+		return err
+	}
+
+	update(settings)
+
+	data, err := yaml.Marshal(settings)
+	if err != nil {
+		return err
+	}
+
+	file := ConfigFilePath()
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		return err
+	}
+
+	viper.SetConfigFile(file)
+	return viper.ReadInConfig()
+}
+
+// lookupConfigValue returns the value under a dotted key in settings
+func lookupConfigValue(settings map[string]any, key string) (any, bool) {
+	parts := strings.Split(key, ".")
+	current := settings
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := current[part].(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		current = next
+	}
+
+	value, ok := current[parts[len(parts)-1]]
+	return value, ok
+}
+
+// setConfigValue stores value under a dotted key in settings, creating parent maps as needed
+func setConfigValue(settings map[string]any, key string, value any) {
+	parts := strings.Split(key, ".")
+	current := settings
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := current[part].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			current[part] = next
+		}
+		current = next
+	}
+
+	current[parts[len(parts)-1]] = value
+}
+
+// deleteConfigValue removes a dotted key from settings
+func deleteConfigValue(settings map[string]any, key string) {
+	parts := strings.Split(key, ".")
+	current := settings
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := current[part].(map[string]any)
+		if !ok {
+			return
+		}
+		current = next
+	}
+
+	delete(current, parts[len(parts)-1])
+}
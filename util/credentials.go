@@ -0,0 +1,171 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// SessionKey is the credential store key holding the SUV session
+	SessionKey = "session"
+	// UserCodeKey is the credential store key holding the user code remembered by login
+	UserCodeKey = "usercode"
+	// PasswordKey is the credential store key holding the password remembered by login
+	PasswordKey = "password"
+)
+
+// credentialKeys lists every key suvctl keeps in a credential store for a profile
+var credentialKeys = []string{SessionKey, UserCodeKey, PasswordKey}
+
+// ErrCredentialNotFound is returned when a credential store holds no value for a key
+var ErrCredentialNotFound = errors.New("credential not found")
+
+// CredentialStore keeps secrets such as the SUV session
+type CredentialStore interface {
+	// Get returns the value stored under key, or ErrCredentialNotFound
+	Get(key string) (string, error)
+	// Set stores value under key, replacing any previous value
+	Set(key, value string) error
+	// Delete removes key from the store, succeeding if it was not present
+	Delete(key string) error
+}
+
+// CredentialBackend represents the different credential stores available
+type CredentialBackend string
+
+const (
+	CredentialKeyring   CredentialBackend = "keyring"
+	CredentialFile      CredentialBackend = "file"
+	CredentialPlaintext CredentialBackend = "plaintext"
+)
+
+// GetCredentialBackend returns the configured credential backend from viper config
+func GetCredentialBackend() (CredentialBackend, error) {
+	backend := viper.GetString("credential_store")
+	switch backend {
+	case "", "keyring":
+		return CredentialKeyring, nil
+	case "file":
+		return CredentialFile, nil
+	case "plaintext":
+		return CredentialPlaintext, nil
+	default:
+		return "", Errorf(KindUsage, "unknown credential store %q (valid values are keyring, file and plaintext)", backend)
+	}
+}
+
+// CredentialOptions configure the file credential store
+type CredentialOptions struct {
+	// File is the credential file, $HOME/.config/suvctl/credentials when empty
+	File string
+	// Prompt, when set, asks for the passphrase of the file if SUVCTL_PASSPHRASE is not set
+	Prompt Prompter
+}
+
+// NewCredentialStore creates the credential store for the given backend
+func NewCredentialStore(backend CredentialBackend, opts CredentialOptions) (CredentialStore, error) {
+	switch backend {
+	case CredentialKeyring:
+		return &keyringStore{}, nil
+	case CredentialFile:
+		return newFileStore(opts.File, opts.Prompt), nil
+	case CredentialPlaintext:
+		return &plaintextStore{}, nil
+	default:
+		return nil, fmt.Errorf("unknown credential store %q", backend)
+	}
+}
+
+// MigratePlaintextSessions moves the sessions left in the config file by previous
+// versions of suvctl, or by the plaintext store, into store. It returns the number of
+// sessions migrated.
+func MigratePlaintextSessions(store CredentialStore) (int, error) {
+	if _, ok := store.(*plaintextStore); ok {
+		return 0, nil
+	}
+
+	settings, err := readConfigFile()
+	if err != nil {
+		return 0, err
+	}
+
+	profiles, err := ListProfiles()
+	if err != nil {
+		return 0, err
+	}
+
+	var keys []string
+	for _, profile := range profiles {
+		key := profile.CredentialKey(SessionKey)
+		if _, ok := lookupConfigValue(settings, key); ok {
+			keys = append(keys, key)
+		}
+	}
+
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	migrated := 0
+	for _, key := range keys {
+		value, _ := lookupConfigValue(settings, key)
+		if session, _ := value.(string); session != "" {
+			if err := store.Set(key, session); err != nil {
+				return migrated, fmt.Errorf("error migrating session to credential store: %w", err)
+			}
+			migrated++
+		}
+	}
+
+	err = UpdateConfig(func(settings map[string]any) {
+		for _, key := range keys {
+			deleteConfigValue(settings, key)
+		}
+	})
+
+	return migrated, err
+}
+
+// plaintextStore keeps credentials in the config file, as suvctl used to do
+type plaintextStore struct{}
+
+func (s *plaintextStore) Get(key string) (string, error) {
+	settings, err := readConfigFile()
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := lookupConfigValue(settings, key)
+	if !ok {
+		return "", ErrCredentialNotFound
+	}
+
+	secret, _ := value.(string)
+	if secret == "" {
+		return "", ErrCredentialNotFound
+	}
+
+	return secret, nil
+}
+
+func (s *plaintextStore) Set(key, value string) error {
+	return UpdateConfig(func(settings map[string]any) {
+		setConfigValue(settings, key, value)
+	})
+}
+
+func (s *plaintextStore) Delete(key string) error {
+	settings, err := readConfigFile()
+	if err != nil {
+		return err
+	}
+	if _, ok := lookupConfigValue(settings, key); !ok {
+		return nil
+	}
+
+	return UpdateConfig(func(settings map[string]any) {
+		deleteConfigValue(settings, key)
+	})
+}
@@ -0,0 +1,153 @@
+package util_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/patitolabs/suvctl/util"
+	"github.com/spf13/viper"
+)
+
+// useConfig makes viper read a config file holding contents, which the plaintext store
+// and the profiles write to
+func useConfig(t *testing.T, contents string) string {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(file, []byte(contents), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	viper.SetConfigFile(file)
+	if err := viper.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(viper.Reset)
+
+	return file
+}
+
+// assertRoundTrip checks that store gives back what was set and forgets what was deleted
+func assertRoundTrip(t *testing.T, store util.CredentialStore) {
+	t.Helper()
+
+	if _, err := store.Get(util.SessionKey); !errors.Is(err, util.ErrCredentialNotFound) {
+		t.Fatalf("empty store: got %v, want ErrCredentialNotFound", err)
+	}
+
+	for key, value := range map[string]string{util.SessionKey: "abc123", "profiles.work.session": "def456"} {
+		if err := store.Set(key, value); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := store.Get(key); err != nil || got != value {
+			t.Fatalf("%s: got %q, %v, want %q", key, got, err, value)
+		}
+	}
+
+	if err := store.Delete(util.SessionKey); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(util.SessionKey); !errors.Is(err, util.ErrCredentialNotFound) {
+		t.Fatalf("deleted key: got %v, want ErrCredentialNotFound", err)
+	}
+	if err := store.Delete(util.SessionKey); err != nil {
+		t.Fatalf("deleting a missing key: %v", err)
+	}
+	if got, _ := store.Get("profiles.work.session"); got != "def456" {
+		t.Fatalf("deleting a key changed another one to %q", got)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	t.Setenv("SUVCTL_PASSPHRASE", "open sesame")
+	file := filepath.Join(t.TempDir(), "credentials")
+
+	store, err := util.NewCredentialStore(util.CredentialFile, util.CredentialOptions{File: file})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertRoundTrip(t, store)
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "def456") {
+		t.Error("the credential file holds the secrets in plaintext")
+	}
+
+	// A wrong passphrase fails instead of giving back what it decrypts to
+	t.Setenv("SUVCTL_PASSPHRASE", "open barley")
+	store, _ = util.NewCredentialStore(util.CredentialFile, util.CredentialOptions{File: file})
+	if got, err := store.Get("profiles.work.session"); util.KindOf(err) != util.KindAuth || got != "" {
+		t.Errorf("wrong passphrase: got %q, %v, want an auth error", got, err)
+	}
+
+	// Without a passphrase nor a prompt it asks for SUVCTL_PASSPHRASE
+	t.Setenv("SUVCTL_PASSPHRASE", "")
+	store, _ = util.NewCredentialStore(util.CredentialFile, util.CredentialOptions{File: file})
+	if _, err := store.Get(util.SessionKey); util.KindOf(err) != util.KindAuth {
+		t.Errorf("no passphrase: got %v, want an auth error", err)
+	}
+}
+
+func TestPlaintextStore(t *testing.T) {
+	file := useConfig(t, "host: suv.example.com\n")
+
+	store, err := util.NewCredentialStore(util.CredentialPlaintext, util.CredentialOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertRoundTrip(t, store)
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "host: suv.example.com") {
+		t.Errorf("the plaintext store dropped the other settings:\n%s", data)
+	}
+}
+
+func TestMigratePlaintextSessions(t *testing.T) {
+	t.Setenv("SUVCTL_PASSPHRASE", "open sesame")
+	file := useConfig(t, "host: suv.example.com\nsession: abc123\nprofiles:\n  work:\n    usercode: \"1023300121\"\n    session: def456\n")
+
+	store, err := util.NewCredentialStore(util.CredentialFile, util.CredentialOptions{File: filepath.Join(t.TempDir(), "credentials")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	migrated, err := util.MigratePlaintextSessions(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if migrated != 2 {
+		t.Errorf("migrated %d sessions, want 2", migrated)
+	}
+
+	for key, want := range map[string]string{util.SessionKey: "abc123", "profiles.work.session": "def456"} {
+		if got, err := store.Get(key); err != nil || got != want {
+			t.Errorf("%s: got %q, %v, want %q", key, got, err, want)
+		}
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "session") {
+		t.Errorf("the sessions were left in the config file:\n%s", data)
+	}
+	if !strings.Contains(string(data), "usercode") || !strings.Contains(string(data), "host") {
+		t.Errorf("the migration dropped other settings:\n%s", data)
+	}
+
+	// Once moved, there is nothing left to migrate
+	if migrated, err := util.MigratePlaintextSessions(store); err != nil || migrated != 0 {
+		t.Errorf("second migration: got %d, %v, want 0", migrated, err)
+	}
+}
@@ -0,0 +1,202 @@
+package util
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/adrg/xdg"
+)
+
+const (
+	// pbkdf2Iterations follows the OWASP recommendation for PBKDF2-HMAC-SHA256
+	pbkdf2Iterations = 600000
+	saltSize         = 16
+	keySize          = 32
+)
+
+// encryptedCredentials is the on-disk layout of the encrypted credential file
+type encryptedCredentials struct {
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// fileStore keeps credentials in a file encrypted with AES-GCM, using a key derived
+// from a passphrase taken from SUVCTL_PASSPHRASE or asked with prompt
+type fileStore struct {
+	path       string
+	passphrase string
+	prompt     Prompter
+}
+
+func newFileStore(file string, prompt Prompter) *fileStore {
+	if file == "" {
+		file = path.Join(xdg.ConfigHome, "suvctl", "credentials")
+	}
+	return &fileStore{path: file, prompt: prompt}
+}
+
+func (s *fileStore) Get(key string) (string, error) {
+	secrets, err := s.load()
+	if err != nil {
+		return "", err
+	}
+
+	secret, ok := secrets[key]
+	if !ok {
+		return "", ErrCredentialNotFound
+	}
+
+	return secret, nil
+}
+
+func (s *fileStore) Set(key, value string) error {
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	secrets[key] = value
+	return s.save(secrets)
+}
+
+func (s *fileStore) Delete(key string) error {
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := secrets[key]; !ok {
+		return nil
+	}
+
+	delete(secrets, key)
+	return s.save(secrets)
+}
+
+func (s *fileStore) getPassphrase() (string, error) {
+	if s.passphrase != "" {
+		return s.passphrase, nil
+	}
+
+	passphrase := os.Getenv("SUVCTL_PASSPHRASE")
+	if passphrase == "" {
+		err := ErrNoTerminal
+		if s.prompt != nil {
+			passphrase, err = s.prompt("Credential store passphrase: ", true)
+		}
+		if errors.Is(err, ErrNoTerminal) {
+			return "", Errorf(KindAuth, "a passphrase is required for the credential file, set SUVCTL_PASSPHRASE")
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if passphrase == "" {
+		return "", Errorf(KindAuth, "the credential file passphrase cannot be empty")
+	}
+
+	s.passphrase = passphrase
+	return passphrase, nil
+}
+
+func (s *fileStore) load() (map[string]string, error) {
+	secrets := map[string]string{}
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return secrets, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var encrypted encryptedCredentials
+	if err := json.Unmarshal(data, &encrypted); err != nil {
+		return nil, fmt.Errorf("error reading credential file %s: %w", s.path, err)
+	}
+
+	passphrase, err := s.getPassphrase()
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newCredentialCipher(passphrase, encrypted.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := aead.Open(nil, encrypted.Nonce, encrypted.Ciphertext, nil)
+	if err != nil {
+		return nil, Errorf(KindAuth, "error decrypting credential file %s: wrong passphrase or corrupted file", s.path)
+	}
+
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, fmt.Errorf("error reading credential file %s: %w", s.path, err)
+	}
+
+	return secrets, nil
+}
+
+func (s *fileStore) save(secrets map[string]string) error {
+	passphrase, err := s.getPassphrase()
+	if err != nil {
+		return err
+	}
+
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+
+	encrypted := encryptedCredentials{Salt: make([]byte, saltSize)}
+	if _, err := rand.Read(encrypted.Salt); err != nil {
+		return err
+	}
+
+	aead, err := newCredentialCipher(passphrase, encrypted.Salt)
+	if err != nil {
+		return err
+	}
+
+	encrypted.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(encrypted.Nonce); err != nil {
+		return err
+	}
+	encrypted.Ciphertext = aead.Seal(nil, encrypted.Nonce, plaintext, nil)
+
+	data, err := json.Marshal(encrypted)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0o600)
+}
+
+func newCredentialCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, pbkdf2Iterations, keySize)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
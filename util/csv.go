@@ -0,0 +1,57 @@
+package util
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// parseDelimiter returns the CSV field delimiter given as delimiter, a comma by default
+func parseDelimiter(delimiter string) (rune, error) {
+	if delimiter == "" {
+		return ',', nil
+	}
+	if delimiter == `\t` {
+		return '\t', nil
+	}
+
+	r, size := utf8.DecodeRuneInString(delimiter)
+	if size != len(delimiter) || r == '"' || r == '\r' || r == '\n' || r == utf8.RuneError {
+		return 0, Errorf(KindUsage, "invalid delimiter %q: use a single character other than a quote or a line break", delimiter)
+	}
+	return r, nil
+}
+
+// outputCSV prints records as CSV, with the delimiter of opts
+func outputCSV(w io.Writer, r records, opts OutputOptions) error {
+	return outputDelimited(w, r, opts.Delimiter, opts.NoHeader)
+}
+
+// outputTSV prints records as tab separated values
+func outputTSV(w io.Writer, r records, opts OutputOptions) error {
+	return outputDelimited(w, r, '\t', opts.NoHeader)
+}
+
+func outputDelimited(w io.Writer, r records, delimiter rune, noHeader bool) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = delimiter
+
+	if !noHeader {
+		writer.Write(r.fields)
+	}
+
+	for _, row := range r.rows {
+		record := make([]string, len(row))
+		for i, value := range row {
+			record[i] = formatRecordValue(value)
+		}
+		writer.Write(record)
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("error writing records: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package util
+
+import "github.com/patitolabs/gosuv2"
+
+// GradeData represents structured grade data for formatting
+type GradeData struct {
+	CourseID     int     `json:"course_id"`
+	CourseName   string  `json:"course_name"`
+	Attempt      int     `json:"attempt"`
+	Average1     float32 `json:"average_1,omitempty"`
+	Average2     float32 `json:"average_2,omitempty"`
+	Average3     float32 `json:"average_3,omitempty"`
+	Average4     float32 `json:"average_4,omitempty"`
+	Average5     float32 `json:"average_5,omitempty"`
+	Average6     float32 `json:"average_6,omitempty"`
+	Substitute   float32 `json:"substitute,omitempty"`
+	Average      float32 `json:"average,omitempty"`
+	Postponed    float32 `json:"postponed,omitempty"`
+	FinalAverage float32 `json:"final_average,omitempty"`
+	Disabled     bool    `json:"disabled"`
+	FinalStatus  string  `json:"final_status"`
+	StatusReason string  `json:"status_reason"`
+}
+
+// StudentData represents structured student data for formatting
+type StudentData struct {
+	StudentID   string `json:"student_id"`
+	StudentName string `json:"student_name"`
+	DNI         string `json:"dni"`
+}
+
+// ProfessorData represents structured professor data for formatting
+type ProfessorData struct {
+	Code          string `json:"code"`
+	ProfessorName string `json:"professor_name"`
+	DNI           string `json:"dni"`
+	WorkerID      string `json:"worker_id"`
+}
+
+// NewGradeData converts the grades of a course returned by SUV into GradeData, with the
+// final status rules give it
+func NewGradeData(grade gosuv2.SuvCurrentCourseGrades, rules StatusRules) GradeData {
+	status := rules.Evaluate(grade)
+
+	return GradeData{
+		CourseID:     grade.CourseID,
+		CourseName:   grade.CourseName,
+		Attempt:      grade.Attempt,
+		Average1:     grade.Average1,
+		Average2:     grade.Average2,
+		Average3:     grade.Average3,
+		Average4:     grade.Average4,
+		Average5:     grade.Average5,
+		Average6:     grade.Average6,
+		Substitute:   grade.Substitute,
+		Average:      grade.Average,
+		Postponed:    grade.Postponed,
+		FinalAverage: grade.FinalAverage,
+		Disabled:     grade.Disabled,
+		FinalStatus:  status.Status,
+		StatusReason: status.Reason,
+	}
+}
+
+// NewStudentData converts a student returned by SUV into StudentData
+func NewStudentData(student gosuv2.StudentBasicResponse) StudentData {
+	return StudentData{
+		StudentID:   student.StudentID,
+		StudentName: student.StudentName,
+		DNI:         student.DNI,
+	}
+}
+
+// NewProfessorData converts a professor returned by SUV into ProfessorData
+func NewProfessorData(professor gosuv2.ProfessorBasicResponse) ProfessorData {
+	return ProfessorData{
+		Code:          professor.Code,
+		ProfessorName: professor.ProfessorName,
+		DNI:           professor.DNI,
+		WorkerID:      professor.WorkerID,
+	}
+}
@@ -0,0 +1,247 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/patitolabs/gosuv2"
+)
+
+// The kinds of GradeChange
+const (
+	ChangeAdded   = "added"
+	ChangeChanged = "changed"
+	ChangeRemoved = "removed"
+)
+
+// ErrNoSnapshots is returned when a snapshot is asked for and the history has none
+var ErrNoSnapshots = NewError(KindNotFound, errors.New("the grade history has no snapshots yet"))
+
+// ErrNoEarlierSnapshot is returned by DiffGrades when the history has no snapshot to
+// compare the grades fetched now with, as on the first run, which starts it
+var ErrNoEarlierSnapshot = NewError(KindNotFound, errors.New("the grade history has no earlier snapshot to compare with, the grades fetched now start it"))
+
+// GradeChange is a value of a course that was added, changed or removed between two
+// snapshots of the grades
+type GradeChange struct {
+	CourseID   int    `json:"course_id"`
+	CourseName string `json:"course_name"`
+	Change     string `json:"change"`
+	Field      string `json:"field"`
+	From       any    `json:"from"`
+	To         any    `json:"to"`
+}
+
+// gradeFieldLabels name the fields of GradeData in sentences
+var gradeFieldLabels = map[string]string{
+	"attempt":       "Attempt",
+	"average_1":     "Unit 1",
+	"average_2":     "Unit 2",
+	"average_3":     "Unit 3",
+	"average_4":     "Unit 4",
+	"average_5":     "Unit 5",
+	"average_6":     "Unit 6",
+	"substitute":    "Substitute exam",
+	"average":       "Average",
+	"postponed":     "Postponed exam",
+	"final_average": "Final average",
+	"disabled":      "Disqualification",
+	"final_status":  "Status",
+}
+
+// fieldChange is a field whose value differs between two GradeData
+type fieldChange struct {
+	field    string
+	from, to any
+}
+
+// gradeFieldChanges lists the fields of a course that differ between from and to, leaving
+// out the ones that name the course or only explain other fields
+func gradeFieldChanges(from, to GradeData) []fieldChange {
+	before := newRecords([]GradeData{from})
+	after := newRecords([]GradeData{to})
+
+	var changes []fieldChange
+	for i, field := range after.fields {
+		if historyIgnoredFields[field] || before.rows[0][i] == after.rows[0][i] {
+			continue
+		}
+		changes = append(changes, fieldChange{field, before.rows[0][i], after.rows[0][i]})
+	}
+	return changes
+}
+
+// CompareGrades lists what changed in the courses selected by filter from one list of
+// courses to the other: a single entry for a course that was added or removed, and one for
+// every value that changed in a course found in both lists, the final statuses given by rules
+func CompareGrades(from, to []gosuv2.SuvCurrentCourseGrades, filter GradeFilter, rules StatusRules) []GradeChange {
+	before := map[int]gosuv2.SuvCurrentCourseGrades{}
+	for _, course := range from {
+		before[course.CourseID] = course
+	}
+	after := map[int]bool{}
+	for _, course := range to {
+		after[course.CourseID] = true
+	}
+
+	changes := []GradeChange{}
+	for _, course := range to {
+		if !filter.matches(course) {
+			continue
+		}
+
+		old, ok := before[course.CourseID]
+		if !ok {
+			changes = append(changes, GradeChange{CourseID: course.CourseID, CourseName: course.CourseName, Change: ChangeAdded})
+			continue
+		}
+
+		for _, change := range gradeFieldChanges(NewGradeData(old, rules), NewGradeData(course, rules)) {
+			changes = append(changes, GradeChange{
+				CourseID:   course.CourseID,
+				CourseName: course.CourseName,
+				Change:     ChangeChanged,
+				Field:      change.field,
+				From:       change.from,
+				To:         change.to,
+			})
+		}
+	}
+	for _, course := range from {
+		if !after[course.CourseID] && filter.matches(course) {
+			changes = append(changes, GradeChange{CourseID: course.CourseID, CourseName: course.CourseName, Change: ChangeRemoved})
+		}
+	}
+
+	return changes
+}
+
+// DiffGrades compares the grades of the courses selected by filter between two snapshots
+// of the grade history, named by ID or date as SelectSnapshot takes them. Without to,
+// the snapshot from is compared with the grades fetched now, and without either of them
+// the last snapshot is, or ErrNoEarlierSnapshot is returned when there is none.
+func (c *Client) DiffGrades(ctx context.Context, from, to string, filter GradeFilter) ([]GradeChange, error) {
+	if from == "" && to != "" {
+		return nil, Errorf(KindUsage, "comparing with a snapshot needs the snapshot to compare it with too")
+	}
+
+	// Read the history before fetching the grades, which adds them to it
+	snapshots, err := c.historySnapshots()
+	if err != nil {
+		return nil, err
+	}
+
+	var before Snapshot
+	if from != "" {
+		if before, err = SelectSnapshot(snapshots, from); err != nil {
+			return nil, err
+		}
+	} else if len(snapshots) > 0 {
+		before = snapshots[len(snapshots)-1]
+	}
+
+	var after Snapshot
+	if to != "" {
+		if after, err = SelectSnapshot(snapshots, to); err != nil {
+			return nil, err
+		}
+	} else {
+		suvGradesResponse, err := c.GradesResponse(ctx)
+		if err != nil {
+			return nil, err
+		}
+		after.Courses = suvGradesResponse.Courses
+
+		// Every course would look added, when nothing changed since there was nothing before
+		if from == "" && len(snapshots) == 0 {
+			return nil, ErrNoEarlierSnapshot
+		}
+	}
+
+	if !filter.empty() && !filter.matchesAny(before.Courses) && !filter.matchesAny(after.Courses) {
+		return nil, ErrNoCoursesFound
+	}
+	return CompareGrades(before.Courses, after.Courses, filter, c.StatusRules), nil
+}
+
+// SelectSnapshot returns the snapshot named by ref: its ID, negative IDs counting from the
+// last snapshot, or a date, as 2006-01-02, 2006-01-02 15:04 or in RFC 3339, naming the
+// last snapshot taken up to then. A date without a time covers the whole day.
+func SelectSnapshot(snapshots []Snapshot, ref string) (Snapshot, error) {
+	if len(snapshots) == 0 {
+		return Snapshot{}, ErrNoSnapshots
+	}
+
+	if id, err := strconv.Atoi(ref); err == nil {
+		index := id - 1
+		if id < 0 {
+			index = len(snapshots) + id
+		}
+		if id == 0 || index < 0 || index >= len(snapshots) {
+			return Snapshot{}, Errorf(KindNotFound, "no snapshot %d, the history has %d", id, len(snapshots))
+		}
+		return snapshots[index], nil
+	}
+
+	until, err := parseSnapshotTime(ref)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		if !snapshots[i].FetchedAt.After(until) {
+			return snapshots[i], nil
+		}
+	}
+	return Snapshot{}, Errorf(KindNotFound, "no snapshot was taken by %s, the first one is from %s", ref, snapshots[0].FetchedAt.Local().Format("2006-01-02 15:04"))
+}
+
+// parseSnapshotTime returns the end of the period named by a date or time, in local time
+func parseSnapshotTime(ref string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, ref); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02 15:04", ref, time.Local); err == nil {
+		return t.Add(time.Minute - time.Nanosecond), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", ref, time.Local); err == nil {
+		return t.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	}
+	return time.Time{}, Errorf(KindUsage, "invalid snapshot %q: use an ID from grades history --snapshots or a date such as 2006-01-02 or 2006-01-02 15:04", ref)
+}
+
+// describe returns the change as a sentence, such as "Unit 2 of course 3403 went from 0.00
+// to 15.00" or "Course 3404 was removed", painting the new value with colors
+func (c GradeChange) describe(colors Colorizer) string {
+	if c.Change != ChangeChanged {
+		return fmt.Sprintf("Course %d (%s) was %s", c.CourseID, c.CourseName, c.Change)
+	}
+	from, to := formatChangeValue(c.From), formatChangeValue(c.To)
+	to = colors.Paint(historyValueColor(colors.theme, c.To), to)
+	return fmt.Sprintf("%s of course %d went from %s to %s", fieldLabel(c.Field), c.CourseID, from, to)
+}
+
+// formatChangeValue shows a value of a GradeChange, grades with two decimals even when
+// they are 0, and "-" when there is none, as for an added or removed course
+func formatChangeValue(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return "-"
+	case float32:
+		return fmt.Sprintf("%.2f", value)
+	default:
+		return formatRecordValue(value)
+	}
+}
+
+// fieldLabel names a field of GradeData as in sentences
+func fieldLabel(field string) string {
+	if label, ok := gradeFieldLabels[field]; ok {
+		return label
+	}
+	return strings.ReplaceAll(field, "_", " ")
+}
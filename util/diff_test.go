@@ -0,0 +1,67 @@
+package util_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/patitolabs/suvctl/util"
+)
+
+func TestCompareGrades(t *testing.T) {
+	snapshots := historySnapshots()
+	first, second := snapshots[0].Courses, snapshots[1].Courses
+
+	if changes := util.CompareGrades(first, first, util.GradeFilter{}, util.DefaultStatusRules); len(changes) != 0 {
+		t.Fatalf("same grades: got %+v", changes)
+	}
+
+	changes := util.CompareGrades(first, second, util.GradeFilter{}, util.DefaultStatusRules)
+	if len(changes) == 0 || changes[0].CourseID != 3403 || changes[0].Field != "average_2" || changes[0].Change != util.ChangeChanged || changes[0].From != float32(0) || changes[0].To != float32(15) {
+		t.Fatalf("unit 2 published: got %+v", changes)
+	}
+
+	// A course missing from one side is a single change of the whole course
+	changes = util.CompareGrades(first, second[:3], util.GradeFilter{CourseIDs: []int{3404}}, util.DefaultStatusRules)
+	if len(changes) != 1 || changes[0].CourseID != 3404 || changes[0].Change != util.ChangeRemoved || changes[0].Field != "" {
+		t.Fatalf("course dropped: got %+v", changes)
+	}
+	changes = util.CompareGrades(first[:3], second, util.GradeFilter{CourseIDs: []int{3404}}, util.DefaultStatusRules)
+	if len(changes) != 1 || changes[0].CourseID != 3404 || changes[0].Change != util.ChangeAdded {
+		t.Fatalf("course added: got %+v", changes)
+	}
+}
+
+func TestSelectSnapshot(t *testing.T) {
+	snapshots := historySnapshots()
+
+	tests := []struct {
+		ref  string
+		want time.Time
+	}{
+		{"1", snapshots[0].FetchedAt},
+		{"-1", snapshots[1].FetchedAt},
+		{"2025-09-01", snapshots[0].FetchedAt},
+		{"2025-09-02 09:59", snapshots[0].FetchedAt},
+		{"2025-09-02 10:00", snapshots[1].FetchedAt},
+		{"2025-12-31T00:00:00Z", snapshots[1].FetchedAt},
+	}
+
+	for _, tt := range tests {
+		got, err := util.SelectSnapshot(snapshots, tt.ref)
+		if err != nil {
+			t.Errorf("SelectSnapshot(%q): %v", tt.ref, err)
+		} else if !got.FetchedAt.Equal(tt.want) {
+			t.Errorf("SelectSnapshot(%q) = snapshot from %v, want %v", tt.ref, got.FetchedAt, tt.want)
+		}
+	}
+
+	for _, ref := range []string{"0", "3", "-3", "2025-08-31", "yesterday"} {
+		if _, err := util.SelectSnapshot(snapshots, ref); err == nil {
+			t.Errorf("SelectSnapshot(%q): want an error", ref)
+		}
+	}
+
+	if _, err := util.SelectSnapshot(nil, "1"); err != util.ErrNoSnapshots {
+		t.Errorf("SelectSnapshot with no snapshots: got %v", err)
+	}
+}
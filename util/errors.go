@@ -0,0 +1,98 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// The exit codes of suvctl. 1 is kept for checks that did not pass, such as grades diff
+// finding changes, so that scripts can tell them apart from errors.
+const (
+	ExitOK          = 0
+	ExitCheckFailed = 1
+	ExitError       = 2
+	ExitUsage       = 3
+	ExitNotFound    = 4
+	ExitAuth        = 5
+	ExitNetwork     = 6
+	ExitServer      = 7
+	ExitInterrupted = 130
+)
+
+// ErrorKind tells what went wrong, so that scripts can act on it through the exit code
+type ErrorKind int
+
+const (
+	// KindOther is any error not of the kinds below, such as failing to write a file
+	KindOther ErrorKind = iota
+	// KindUsage is a bad flag, argument or setting
+	KindUsage
+	// KindNotFound is a course, profile or snapshot that does not exist
+	KindNotFound
+	// KindAuth is a session or credentials that SUV does not accept, or missing ones
+	KindAuth
+	// KindNetwork is a failure to reach SUV
+	KindNetwork
+	// KindServer is SUV answering with an error or something unexpected
+	KindServer
+)
+
+var exitCodes = map[ErrorKind]int{
+	KindOther:    ExitError,
+	KindUsage:    ExitUsage,
+	KindNotFound: ExitNotFound,
+	KindAuth:     ExitAuth,
+	KindNetwork:  ExitNetwork,
+	KindServer:   ExitServer,
+}
+
+// Error is an error of a known kind. Wrapping it keeps the kind, the outermost Error
+// deciding it when there are several.
+type Error struct {
+	Kind ErrorKind
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// NewError returns err as an error of kind, or nil when err is nil
+func NewError(kind ErrorKind, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Kind: kind, Err: err}
+}
+
+// Errorf formats an error of kind as fmt.Errorf does
+func Errorf(kind ErrorKind, format string, args ...any) error {
+	return &Error{Kind: kind, Err: fmt.Errorf(format, args...)}
+}
+
+// KindOf returns the kind of err, KindOther when it has none
+func KindOf(err error) ErrorKind {
+	var kindErr *Error
+	if errors.As(err, &kindErr) {
+		return kindErr.Kind
+	}
+	return KindOther
+}
+
+// ExitCode returns the exit code for err: ExitOK when it is nil, ExitInterrupted when it
+// comes from a canceled request, and the one of its kind otherwise
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, context.Canceled):
+		return ExitInterrupted
+	default:
+		return exitCodes[KindOf(err)]
+	}
+}
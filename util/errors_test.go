@@ -0,0 +1,101 @@
+package util_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/patitolabs/gosuv2"
+	"github.com/patitolabs/suvctl/util"
+	"github.com/patitolabs/suvctl/util/suvtest"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, util.ExitOK},
+		{errors.New("disk full"), util.ExitError},
+		{util.Errorf(util.KindUsage, "unknown color mode %q", "sometimes"), util.ExitUsage},
+		{util.ErrNoCoursesFound, util.ExitNotFound},
+		{fmt.Errorf("%w: login page", util.ErrSessionExpired), util.ExitAuth},
+		{fmt.Errorf("fetching grades: %w", context.Canceled), util.ExitInterrupted},
+	}
+
+	for _, tt := range tests {
+		if got := util.ExitCode(tt.err); got != tt.want {
+			t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestBackendErrorKinds(t *testing.T) {
+	ctx := context.Background()
+
+	server := suvtest.NewServer(suvtest.DefaultFixtures())
+	backend := server.Backend(false)
+
+	if _, err := backend.Login(ctx, "1023300121", "wrong"); util.KindOf(err) != util.KindAuth {
+		t.Errorf("rejected login: got %v", err)
+	}
+	backend.LoadPhpSession("unknown")
+	if _, err := backend.GetSuvGradesResponse(ctx); util.KindOf(err) != util.KindAuth {
+		t.Errorf("unknown session: got %v", err)
+	}
+
+	server.Close()
+	if _, err := backend.GetSuvGradesResponse(ctx); util.KindOf(err) != util.KindNetwork {
+		t.Errorf("server down: got %v", err)
+	}
+
+	failing := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "maintenance", http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+
+	host, _ := url.Parse(failing.URL)
+	backend = util.NewSuvBackend(&gosuv2.SuvConfig{Host: host.Host}, failing.Client().Transport)
+	backend.LoadPhpSession("session")
+	if _, err := backend.GetSuvGradesResponse(ctx); util.KindOf(err) != util.KindServer {
+		t.Errorf("server error: got %v", err)
+	}
+
+	// Only the login page tells that the session expired, other unexpected answers are
+	// server errors
+	malformed := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error": "database unavailable"}`))
+	}))
+	defer malformed.Close()
+
+	host, _ = url.Parse(malformed.URL)
+	backend = util.NewSuvBackend(&gosuv2.SuvConfig{Host: host.Host}, malformed.Client().Transport)
+	backend.LoadPhpSession("session")
+	if _, err := backend.GetSuvGradesResponse(ctx); errors.Is(err, util.ErrSessionExpired) || util.KindOf(err) != util.KindServer {
+		t.Errorf("malformed answer: got %v", err)
+	}
+
+	// Neither are the HTML pages of a proxy that cannot reach SUV or of a captive portal
+	for status, page := range map[int]string{
+		http.StatusBadGateway: "<html><head><title>502 Bad Gateway</title></head><body><h1>502 Bad Gateway</h1></body></html>",
+		http.StatusOK:         `<html><body><form action="/portal/accept" method="post"><input name="email"></form></body></html>`,
+	} {
+		proxy := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(status)
+			w.Write([]byte(page))
+		}))
+
+		host, _ = url.Parse(proxy.URL)
+		backend = util.NewSuvBackend(&gosuv2.SuvConfig{Host: host.Host}, proxy.Client().Transport)
+		backend.LoadPhpSession("session")
+		if _, err := backend.GetSuvGradesResponse(ctx); errors.Is(err, util.ErrSessionExpired) || util.KindOf(err) != util.KindServer {
+			t.Errorf("HTML page with status %d: got %v", status, err)
+		}
+		proxy.Close()
+	}
+}
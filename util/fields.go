@@ -0,0 +1,330 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// records holds results as rows of values named after their JSON fields, so that they can
+// be projected on some of those fields and printed in any format
+type records struct {
+	fields []string
+	rows   [][]any
+}
+
+// newRecords turns data, a slice of structs, into records
+func newRecords[T any](data []T) records {
+	fields := recordFields(reflect.TypeFor[T]())
+
+	r := records{fields: make([]string, len(fields)), rows: make([][]any, len(data))}
+	for i, field := range fields {
+		r.fields[i] = field.name
+	}
+	for i, item := range data {
+		value := reflect.ValueOf(item)
+		r.rows[i] = make([]any, len(fields))
+		for j, field := range fields {
+			r.rows[i][j] = value.Field(field.index).Interface()
+		}
+	}
+	return r
+}
+
+// project returns the records with only the given fields, in the given order
+func (r records) project(fields []string) (records, error) {
+	indexes := make([]int, len(fields))
+	for i, field := range fields {
+		indexes[i] = r.fieldIndex(field)
+		if indexes[i] < 0 {
+			return records{}, r.unknownField(field)
+		}
+	}
+
+	projected := records{fields: fields, rows: make([][]any, len(r.rows))}
+	for i, row := range r.rows {
+		projected.rows[i] = make([]any, len(indexes))
+		for j, index := range indexes {
+			projected.rows[i][j] = row[index]
+		}
+	}
+	return projected, nil
+}
+
+func (r records) fieldIndex(field string) int {
+	for i, name := range r.fields {
+		if name == field {
+			return i
+		}
+	}
+	return -1
+}
+
+func (r records) unknownField(field string) error {
+	return Errorf(KindUsage, "unknown field %q (available fields: %s)", field, strings.Join(r.fields, ", "))
+}
+
+// jsonObject encodes values as a JSON object keeping the order of the fields
+func jsonObject(fields []string, values []any) (json.RawMessage, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, field := range fields {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(field)
+		value, err := json.Marshal(values[i])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// recordField is an exported field of a struct and its name in JSON
+type recordField struct {
+	name  string
+	index int
+}
+
+// recordFields lists the fields of a struct type that are encoded in JSON, in order
+func recordFields(t reflect.Type) []recordField {
+	var fields []recordField
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fields = append(fields, recordField{name: name, index: i})
+	}
+	return fields
+}
+
+// formatRecordValue turns a value into the text of a record, writing floats with the
+// fewest digits that keep their value
+func formatRecordValue(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case float32:
+		return strconv.FormatFloat(float64(value), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case time.Time:
+		return value.Format(time.RFC3339)
+	case []int:
+		numbers := make([]string, len(value))
+		for i, number := range value {
+			numbers[i] = strconv.Itoa(number)
+		}
+		return strings.Join(numbers, " ")
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// parseFields returns the fields given with --fields, each value possibly listing several
+// of them separated by commas, or nil for all of them
+func parseFields(values []string) []string {
+	var fields []string
+	for _, field := range values {
+		for _, name := range strings.Split(field, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				fields = append(fields, name)
+			}
+		}
+	}
+	return fields
+}
+
+// selection is what --fields or --jsonpath select from a list of results
+type selection struct {
+	records
+	// scalar is set when a single field was selected, so that JSON output lists its values
+	scalar bool
+	// single is set when a single item was selected, so that JSON output shows it alone
+	single bool
+}
+
+// jsonValue returns the value printed by the json and raw formats
+func (s selection) jsonValue() (any, error) {
+	values := make([]any, len(s.rows))
+	for i, row := range s.rows {
+		if s.scalar {
+			values[i] = row[0]
+			continue
+		}
+
+		object, err := jsonObject(s.fields, row)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = object
+	}
+
+	if s.single && len(values) == 1 {
+		return values[0], nil
+	}
+	return values, nil
+}
+
+// selectRecords applies fields or the JSONPath expression path to data, as --fields and
+// --jsonpath, returning nil when neither was given
+func selectRecords[T any](data []T, fields []string, path string) (*selection, error) {
+	switch {
+	case len(fields) > 0 && path != "":
+		return nil, Errorf(KindUsage, "--fields and --jsonpath cannot be used together")
+	case len(fields) > 0:
+		projected, err := newRecords(data).project(fields)
+		if err != nil {
+			return nil, err
+		}
+		return &selection{records: projected}, nil
+	case path != "":
+		return evalJSONPath(path, newRecords(data))
+	default:
+		return nil, nil
+	}
+}
+
+// jsonPath is a parsed JSONPath expression over a list of results, made of an optional
+// item step, [*], [n] or [start:end], followed by an optional field step, .name, .* or
+// ['name','other']
+type jsonPath struct {
+	start, end int  // items in [start, end)
+	hasEnd     bool // false when the end was left out, meaning the end of the list
+	single     bool
+	fields     []string // nil for all of them
+}
+
+// evalJSONPath selects the results matched by a JSONPath expression such as {.[*].course_name}
+func evalJSONPath(expression string, r records) (*selection, error) {
+	path, err := parseJSONPath(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	start, end := path.start, path.end
+	if start < 0 {
+		start += len(r.rows)
+	}
+	switch {
+	case !path.hasEnd:
+		end = len(r.rows)
+	case end < 0:
+		end += len(r.rows)
+	}
+	if path.single {
+		if start < 0 || start >= len(r.rows) {
+			return nil, Errorf(KindUsage, "jsonpath %s: index %d out of range", expression, path.start)
+		}
+		end = start + 1
+	}
+	start = max(min(start, len(r.rows)), 0)
+	end = max(min(end, len(r.rows)), start)
+
+	selected := records{fields: r.fields, rows: r.rows[start:end]}
+	if path.fields != nil {
+		if selected, err = selected.project(path.fields); err != nil {
+			return nil, Errorf(KindUsage, "jsonpath %s: %w", expression, err)
+		}
+	}
+
+	return &selection{records: selected, scalar: len(path.fields) == 1, single: path.single}, nil
+}
+
+func parseJSONPath(expression string) (jsonPath, error) {
+	invalid := func(reason string) (jsonPath, error) {
+		return jsonPath{}, Errorf(KindUsage, "invalid jsonpath %s: %s", expression, reason)
+	}
+
+	rest := strings.TrimSpace(expression)
+	if strings.HasPrefix(rest, "{") {
+		if !strings.HasSuffix(rest, "}") {
+			return invalid("missing closing }")
+		}
+		rest = rest[1 : len(rest)-1]
+	}
+	rest = strings.TrimPrefix(rest, "$")
+	rest = strings.TrimPrefix(rest, ".")
+
+	var path jsonPath
+	if strings.HasPrefix(rest, "[") && !strings.HasPrefix(rest, "['") && !strings.HasPrefix(rest, `["`) {
+		index, after, ok := strings.Cut(rest[1:], "]")
+		if !ok {
+			return invalid("missing closing ]")
+		}
+		rest = after
+
+		switch {
+		case index == "*":
+		case strings.Contains(index, ":"):
+			from, to, _ := strings.Cut(index, ":")
+			var err error
+			if path.start, err = parseJSONPathIndex(from); err != nil {
+				return invalid(err.Error())
+			}
+			if path.end, err = parseJSONPathIndex(to); err != nil {
+				return invalid(err.Error())
+			}
+			path.hasEnd = to != ""
+		default:
+			n, err := strconv.Atoi(index)
+			if err != nil {
+				return invalid(fmt.Sprintf("bad index %q", index))
+			}
+			path.start, path.single = n, true
+		}
+	} else if rest != "" {
+		return invalid("select the items of the list first, as in {.[*].course_name}")
+	}
+
+	switch {
+	case rest == "" || rest == ".*" || rest == "[*]":
+	case strings.HasPrefix(rest, "['") || strings.HasPrefix(rest, `["`):
+		if !strings.HasSuffix(rest, "]") {
+			return invalid("missing closing ]")
+		}
+		for _, name := range strings.Split(rest[1:len(rest)-1], ",") {
+			name = strings.Trim(strings.TrimSpace(name), `'"`)
+			if name == "" {
+				return invalid("empty field name")
+			}
+			path.fields = append(path.fields, name)
+		}
+	case strings.HasPrefix(rest, ".") && !strings.ContainsAny(rest[1:], ".[]"):
+		path.fields = []string{rest[1:]}
+	default:
+		return invalid(fmt.Sprintf("unsupported expression %q, results only have one level of fields", rest))
+	}
+
+	return path, nil
+}
+
+func parseJSONPathIndex(index string) (int, error) {
+	if index == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(index)
+	if err != nil {
+		return 0, fmt.Errorf("bad index %q", index)
+	}
+	return n, nil
+}
@@ -1,131 +1,150 @@
 package util
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"os"
-	"strconv"
+	"io"
 	"strings"
+	"time"
 
 	"github.com/patitolabs/gosuv2"
-	"github.com/spf13/cobra"
 )
 
-func (c *Client) ListGrades() {
-	suvGradesResponse, err := c.SuvClient.GetSuvGradesResponse()
-	cobra.CheckErr(err)
+// ErrNoCoursesFound is returned when no course matches a GradeFilter
+var ErrNoCoursesFound = NewError(KindNotFound, errors.New("no courses found"))
 
-	OutputGrades(suvGradesResponse.Courses)
+// GradeFilter selects courses by ID or by a substring of their name. Courses matching any
+// of the criteria are kept, and an empty filter keeps every course.
+type GradeFilter struct {
+	CourseIDs   []int
+	CourseNames []string
 }
 
-func (c *Client) ListGradesByCourseId(courseId []string) {
-	suvGradesResponse, err := c.SuvClient.GetSuvGradesResponse()
-	cobra.CheckErr(err)
-
-	courseIdMap := make(map[int]struct{})
-	for _, id := range courseId {
-		courseIdInt, err := strconv.Atoi(id)
-		if err != nil {
-			fmt.Printf("Invalid course ID: %s\n", id)
-			continue
+// GradesResponse fetches the current period and its grades as returned by SUV
+func (c *Client) GradesResponse(ctx context.Context) (*gosuv2.SuvGradesResponse, error) {
+	var suvGradesResponse *gosuv2.SuvGradesResponse
+
+	err := c.withSession(ctx, func() error {
+		var err error
+		suvGradesResponse, err = c.Backend.GetSuvGradesResponse(ctx)
+		return err
+	})
+
+	if err == nil && c.History != nil {
+		snapshot := Snapshot{
+			FetchedAt: time.Now(),
+			Semester:  suvGradesResponse.Semester,
+			Courses:   suvGradesResponse.Courses,
+		}
+		if err := c.History.Record(c.Profile.Name, snapshot); err != nil && c.OnHistoryError != nil {
+			c.OnHistoryError(err)
 		}
-		courseIdMap[courseIdInt] = struct{}{}
 	}
 
-	found := false
-	var foundGrades []gosuv2.SuvCurrentCourseGrades
-	for _, grade := range suvGradesResponse.Courses {
-		if _, exists := courseIdMap[grade.CourseID]; exists {
-			foundGrades = append(foundGrades, grade)
-			found = true
-		}
+	return suvGradesResponse, err
+}
+
+// Grades returns the grades of the current period for the courses selected by filter.
+// It returns ErrNoCoursesFound when a non-empty filter matches no course.
+func (c *Client) Grades(ctx context.Context, filter GradeFilter) ([]GradeData, error) {
+	suvGradesResponse, err := c.GradesResponse(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	if !found {
-		fmt.Println("No courses found.")
-		os.Exit(1)
+	grades := filterGrades(suvGradesResponse.Courses, filter, c.StatusRules)
+	if len(grades) == 0 && !filter.empty() {
+		return nil, ErrNoCoursesFound
+	}
+
+	return grades, nil
+}
+
+// filterGrades converts the courses selected by filter into GradeData, giving them their
+// status by rules
+func filterGrades(courses []gosuv2.SuvCurrentCourseGrades, filter GradeFilter, rules StatusRules) []GradeData {
+	grades := make([]GradeData, 0, len(courses))
+	for _, grade := range courses {
+		if filter.matches(grade) {
+			grades = append(grades, NewGradeData(grade, rules))
+		}
 	}
+	return grades
+}
 
-	OutputGrades(foundGrades)
+func (f GradeFilter) empty() bool {
+	return len(f.CourseIDs) == 0 && len(f.CourseNames) == 0
 }
 
-func (c *Client) ListGradesByCourseName(courseName []string) {
-	suvGradesResponse, err := c.SuvClient.GetSuvGradesResponse()
-	cobra.CheckErr(err)
-
-	found := false
-	var foundGrades []gosuv2.SuvCurrentCourseGrades
-	for _, grade := range suvGradesResponse.Courses {
-		for _, name := range courseName {
-			if strings.Contains(grade.CourseName, name) {
-				foundGrades = append(foundGrades, grade)
-				found = true
-				break
-			}
+func (f GradeFilter) matches(grade gosuv2.SuvCurrentCourseGrades) bool {
+	if f.empty() {
+		return true
+	}
+
+	for _, id := range f.CourseIDs {
+		if grade.CourseID == id {
+			return true
 		}
 	}
 
-	if !found {
-		fmt.Println("No courses found.")
-		os.Exit(1)
+	for _, name := range f.CourseNames {
+		if strings.Contains(grade.CourseName, name) {
+			return true
+		}
 	}
 
-	OutputGrades(foundGrades)
+	return false
+}
+
+func (f GradeFilter) matchesAny(grades []gosuv2.SuvCurrentCourseGrades) bool {
+	for _, grade := range grades {
+		if f.matches(grade) {
+			return true
+		}
+	}
+	return false
 }
 
-func prettyPrintGradeCourse(grade gosuv2.SuvCurrentCourseGrades) {
-	fmt.Println("Course ID:", grade.CourseID)
-	fmt.Println("Course:", grade.CourseName)
-	fmt.Println("Time:", grade.Attempt)
-	printAverage(grade.Average1, "Average of Unit 1:")
-	printAverage(grade.Average2, "Average of Unit 2:")
-	printAverage(grade.Average3, "Average of Unit 3:")
-	printAverage(grade.Average4, "Average of Unit 4:")
-	printAverage(grade.Average5, "Average of Unit 5:")
-	printAverage(grade.Average6, "Average of Unit 6:")
-	printAverage(grade.Substitute, "Substitute exam:")
-	printAverage(grade.Average, "Course Average:")
-	printAverage(grade.Postponed, "Failed:")
-	printAverage(grade.FinalAverage, "Course Final Average:")
+func prettyPrintGradeCourse(w io.Writer, grade GradeData, colors Colorizer) {
+	fmt.Fprintln(w, "Course ID:", grade.CourseID)
+	fmt.Fprintln(w, "Course:", grade.CourseName)
+	fmt.Fprintln(w, "Time:", grade.Attempt)
+	printAverage(w, colors, grade.Average1, "Average of Unit 1:")
+	printAverage(w, colors, grade.Average2, "Average of Unit 2:")
+	printAverage(w, colors, grade.Average3, "Average of Unit 3:")
+	printAverage(w, colors, grade.Average4, "Average of Unit 4:")
+	printAverage(w, colors, grade.Average5, "Average of Unit 5:")
+	printAverage(w, colors, grade.Average6, "Average of Unit 6:")
+	printAverage(w, colors, grade.Substitute, "Substitute exam:")
+	printAverage(w, colors, grade.Average, "Course Average:")
+	printAverage(w, colors, grade.Postponed, "Failed:")
+	printAverage(w, colors, grade.FinalAverage, "Course Final Average:")
 
 	if grade.Disabled {
-		fmt.Println("\033[31mWarning: the student was disqualified in this course\033[0m")
+		fmt.Fprintln(w, colors.Paint(colors.theme.Warning, "Warning: the student was disqualified in this course"))
 	}
 
-	printFinalStatus(grade)
+	printFinalStatus(w, colors, grade)
 }
 
-func printAverage(grade float32, message string) {
+func printAverage(w io.Writer, colors Colorizer, grade float32, message string) {
 	if grade != 0 {
-		printGrade(grade, message)
+		printGrade(w, colors, grade, message)
 	}
 }
 
-func printGrade(grade float32, message string) {
-	// If grade < 13.5 print the message in the default color, and the number in red
-	// Else, print the message in the default color, and the number in light blue
-	if grade < 13.5 {
-		fmt.Printf("%s \033[31m%.2f\033[0m\n", message, grade)
-	} else {
-		fmt.Printf("%s \033[94m%.2f\033[0m\n", message, grade)
-	}
+func printGrade(w io.Writer, colors Colorizer, grade float32, message string) {
+	// Print the message in the default color, and the number in the color of its band in
+	// the theme
+	fmt.Fprintf(w, "%s %s\n", message, colors.Paint(colors.theme.GradeColor(grade), fmt.Sprintf("%.2f", grade)))
 }
 
-func printFinalStatus(grade gosuv2.SuvCurrentCourseGrades) {
-	if grade.FinalStatus == gosuv2.PassedStatus {
-		// Print the final status in green
-		fmt.Println("Final status: \033[32mPASSED\033[0m")
-	} else {
-		if grade.Average1 != 0 && grade.Average2 != 0 && grade.Average3 != 0 {
-			if grade.Average >= 14 || grade.FinalAverage >= 14 {
-				// Print the final status in green, student passed
-				fmt.Println("Final status: \033[32mPASSED\033[0m")
-			} else {
-				// Print the final status in red, student failed
-				fmt.Println("Final status: \033[31mFAILED\033[0m")
-			}
-		} else {
-			// Print the final status in yellow, semester isn't over yet
-			fmt.Println("Final status: \033[33mPENDING\033[0m")
-		}
+func printFinalStatus(w io.Writer, colors Colorizer, grade GradeData) {
+	// Colored by the theme, green when passed, red when failed and yellow while the
+	// semester isn't over yet by default
+	fmt.Fprintf(w, "Final status: %s\n", colors.Paint(colors.theme.StatusColor(grade.FinalStatus), grade.FinalStatus))
+	if grade.StatusReason != "" {
+		fmt.Fprintln(w, "Reason:", grade.StatusReason)
 	}
 }
@@ -0,0 +1,277 @@
+package util
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/patitolabs/gosuv2"
+	"github.com/spf13/viper"
+	bolt "go.etcd.io/bbolt"
+)
+
+// historyLockTimeout is how long to wait for another suvctl, such as grades watch, to
+// release the history file
+const historyLockTimeout = 5 * time.Second
+
+// History keeps a snapshot of the grades every time they are fetched and differ from the
+// last one, in a bbolt file with a bucket for each profile holding the snapshots keyed by
+// the time they were taken. The file is only opened while reading or writing, so that
+// several suvctl can share it.
+type History struct {
+	path  string
+	limit int
+}
+
+// Snapshot is the current period as fetched at some point
+type Snapshot struct {
+	FetchedAt time.Time                       `json:"fetched_at"`
+	Semester  string                          `json:"semester"`
+	Courses   []gosuv2.SuvCurrentCourseGrades `json:"courses"`
+}
+
+// HistoryEntry is a change of a field of a course, or the first value seen for it
+type HistoryEntry struct {
+	Time       time.Time `json:"time"`
+	CourseID   int       `json:"course_id"`
+	CourseName string    `json:"course_name"`
+	Field      string    `json:"field"`
+	From       any       `json:"from"`
+	To         any       `json:"to"`
+}
+
+// SnapshotInfo describes a snapshot of the grade history, which other commands refer to by
+// its ID, its position in the history starting at 1
+type SnapshotInfo struct {
+	ID        int       `json:"id"`
+	FetchedAt time.Time `json:"fetched_at"`
+	Semester  string    `json:"semester"`
+	Courses   int       `json:"courses"`
+}
+
+// HistoryFilePath returns the history file from viper config, or the default one
+func HistoryFilePath() string {
+	if file := viper.GetString("history_file"); file != "" {
+		return file
+	}
+	return path.Join(xdg.DataHome, "suvctl", "history.db")
+}
+
+// NewHistory returns the history kept in the file at path, which keeps up to limit
+// snapshots for each profile, dropping the oldest ones, or all of them when limit is 0
+func NewHistory(path string, limit int) *History {
+	return &History{path: path, limit: limit}
+}
+
+// Record adds a snapshot to the history of profile, unless the grades are the same as in
+// the snapshot before it
+func (h *History) Record(profile string, snapshot Snapshot) error {
+	value, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	content, err := snapshotContent(snapshot)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o700); err != nil {
+		return err
+	}
+	db, err := bolt.Open(h.path, 0o600, &bolt.Options{Timeout: historyLockTimeout})
+	if err != nil {
+		return fmt.Errorf("error opening grade history: %w", err)
+	}
+	defer db.Close()
+
+	return db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(profile))
+		if err != nil {
+			return err
+		}
+
+		key := snapshotKey(snapshot.FetchedAt)
+		if previous := snapshotBefore(bucket, key); previous != nil {
+			var last Snapshot
+			if err := json.Unmarshal(previous, &last); err != nil {
+				return fmt.Errorf("error reading grade history: %w", err)
+			}
+			if lastContent, err := snapshotContent(last); err == nil && bytes.Equal(lastContent, content) {
+				return nil
+			}
+		}
+
+		if err := bucket.Put(key, value); err != nil {
+			return err
+		}
+		return h.prune(bucket)
+	})
+}
+
+// snapshotBefore returns the last snapshot in bucket taken before key, or nil
+func snapshotBefore(bucket *bolt.Bucket, key []byte) []byte {
+	cursor := bucket.Cursor()
+	if k, _ := cursor.Seek(key); k == nil {
+		_, value := cursor.Last()
+		return value
+	}
+	_, value := cursor.Prev()
+	return value
+}
+
+// prune drops the oldest snapshots of bucket beyond the limit of the history
+func (h *History) prune(bucket *bolt.Bucket) error {
+	if h.limit <= 0 {
+		return nil
+	}
+
+	// Keys are collected first, as deleting while iterating skips some
+	var keys [][]byte
+	cursor := bucket.Cursor()
+	for k, _ := cursor.First(); k != nil; k, _ = cursor.Next() {
+		keys = append(keys, k)
+	}
+	for len(keys) > h.limit {
+		if err := bucket.Delete(keys[0]); err != nil {
+			return err
+		}
+		keys = keys[1:]
+	}
+	return nil
+}
+
+// snapshotContent returns the grades of snapshot without the time they were fetched, to
+// tell whether two snapshots hold the same grades
+func snapshotContent(snapshot Snapshot) ([]byte, error) {
+	snapshot.FetchedAt = time.Time{}
+	return json.Marshal(snapshot)
+}
+
+// Snapshots returns the snapshots of profile from the oldest to the newest one
+func (h *History) Snapshots(profile string) ([]Snapshot, error) {
+	if _, err := os.Stat(h.path); errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	db, err := bolt.Open(h.path, 0o600, &bolt.Options{Timeout: historyLockTimeout, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("error opening grade history: %w", err)
+	}
+	defer db.Close()
+
+	var snapshots []Snapshot
+	err = db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(profile))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(key, value []byte) error {
+			var snapshot Snapshot
+			if err := json.Unmarshal(value, &snapshot); err != nil {
+				return fmt.Errorf("error reading grade history: %w", err)
+			}
+			snapshots = append(snapshots, snapshot)
+			return nil
+		})
+	})
+
+	return snapshots, err
+}
+
+// snapshotKey orders snapshots by time, as bbolt sorts keys bytewise
+func snapshotKey(t time.Time) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(t.UnixNano()))
+	return key
+}
+
+// historyIgnoredFields are the fields of GradeData left out of the timeline, as they name
+// the course or only explain other fields
+var historyIgnoredFields = map[string]bool{
+	"course_id":     true,
+	"course_name":   true,
+	"status_reason": true,
+}
+
+// Timeline lists, for the courses selected by filter, the values each field of their
+// GradeData took over snapshots, in order: the first value seen for it, leaving out the
+// empty ones, and then every change. rules give the final statuses.
+func Timeline(snapshots []Snapshot, filter GradeFilter, rules StatusRules) []HistoryEntry {
+	entries := []HistoryEntry{}
+	last := map[int]GradeData{}
+
+	for _, snapshot := range snapshots {
+		for _, course := range snapshot.Courses {
+			if !filter.matches(course) {
+				continue
+			}
+
+			current := NewGradeData(course, rules)
+			previous, seen := last[course.CourseID]
+			last[course.CourseID] = current
+
+			for _, change := range gradeFieldChanges(previous, current) {
+				entry := HistoryEntry{
+					Time:       snapshot.FetchedAt,
+					CourseID:   course.CourseID,
+					CourseName: course.CourseName,
+					Field:      change.field,
+					To:         change.to,
+				}
+				if seen {
+					entry.From = change.from
+				}
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	// Group the timeline by course, keeping it in order within each course
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].CourseID < entries[j].CourseID })
+	return entries
+}
+
+// GradeHistory returns the timeline of the courses selected by filter in the history of
+// the profile of the client
+func (c *Client) GradeHistory(filter GradeFilter) ([]HistoryEntry, error) {
+	snapshots, err := c.historySnapshots()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := Timeline(snapshots, filter, c.StatusRules)
+	if len(entries) == 0 && !filter.empty() {
+		return nil, ErrNoCoursesFound
+	}
+	return entries, nil
+}
+
+// Snapshots describes the snapshots of the grade history of the profile of the client
+func (c *Client) Snapshots() ([]SnapshotInfo, error) {
+	snapshots, err := c.historySnapshots()
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]SnapshotInfo, len(snapshots))
+	for i, snapshot := range snapshots {
+		infos[i] = SnapshotInfo{ID: i + 1, FetchedAt: snapshot.FetchedAt, Semester: snapshot.Semester, Courses: len(snapshot.Courses)}
+	}
+	return infos, nil
+}
+
+func (c *Client) historySnapshots() ([]Snapshot, error) {
+	if c.History == nil {
+		return nil, Errorf(KindUsage, "the grade history is disabled, set history to true in the config file")
+	}
+	return c.History.Snapshots(c.Profile.Name)
+}
@@ -0,0 +1,95 @@
+package util_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/patitolabs/suvctl/util"
+	"github.com/patitolabs/suvctl/util/suvtest"
+)
+
+// historySnapshots returns two snapshots of the fixtures a day apart, unit 2 of course 3403
+// being published in the second one
+func historySnapshots() []util.Snapshot {
+	response := suvtest.DefaultFixtures().GradesResponse()
+	first := util.Snapshot{
+		FetchedAt: time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC),
+		Semester:  response.Semester,
+		Courses:   response.Courses,
+	}
+
+	second := first
+	second.FetchedAt = first.FetchedAt.Add(24 * time.Hour)
+	second.Courses = append(second.Courses[:0:0], first.Courses...)
+	second.Courses[2].Average2 = 15
+	second.Courses[2].Statuses = []int{1, 1, 0}
+
+	return []util.Snapshot{first, second}
+}
+
+func TestHistory(t *testing.T) {
+	history := util.NewHistory(filepath.Join(t.TempDir(), "suvctl", "history.db"), 0)
+
+	if snapshots, err := history.Snapshots("default"); err != nil || len(snapshots) != 0 {
+		t.Fatalf("empty history: got %v, %v", snapshots, err)
+	}
+
+	// Record them out of order, they are kept sorted by time
+	snapshots := historySnapshots()
+	for _, i := range []int{1, 0} {
+		if err := history.Record("default", snapshots[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := history.Record("other", snapshots[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := history.Snapshots("default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || !got[0].FetchedAt.Equal(snapshots[0].FetchedAt) || got[1].Courses[2].Average2 != 15 {
+		t.Fatalf("unexpected snapshots: %+v", got)
+	}
+
+	entries := util.Timeline(got, util.GradeFilter{CourseIDs: []int{3403}}, util.DefaultStatusRules)
+	changed := entries[len(entries)-1]
+	if changed.Field != "average_2" || changed.From != float32(0) || changed.To != float32(15) || !changed.Time.Equal(snapshots[1].FetchedAt) {
+		t.Fatalf("unexpected last entry: %+v", changed)
+	}
+}
+
+func TestHistoryRecordsChanges(t *testing.T) {
+	history := util.NewHistory(filepath.Join(t.TempDir(), "history.db"), 2)
+	snapshots := historySnapshots()
+
+	// Fetching the same grades again adds no snapshot
+	unchanged := snapshots[0]
+	unchanged.FetchedAt = unchanged.FetchedAt.Add(time.Hour)
+	for _, snapshot := range []util.Snapshot{snapshots[0], unchanged} {
+		if err := history.Record("default", snapshot); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, err := history.Snapshots("default"); err != nil || len(got) != 1 {
+		t.Fatalf("same grades: got %d snapshots, %v", len(got), err)
+	}
+
+	// Beyond the limit the oldest snapshots are dropped
+	third := snapshots[0]
+	third.FetchedAt = snapshots[1].FetchedAt.Add(24 * time.Hour)
+	for _, snapshot := range []util.Snapshot{snapshots[1], third} {
+		if err := history.Record("default", snapshot); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := history.Snapshots("default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || !got[0].FetchedAt.Equal(snapshots[1].FetchedAt) || !got[1].FetchedAt.Equal(third.FetchedAt) {
+		t.Fatalf("over the limit: got %+v", got)
+	}
+}
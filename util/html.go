@@ -0,0 +1,81 @@
+package util
+
+import (
+	"html/template"
+	"io"
+)
+
+// htmlCell is a table cell as shown in HTML output
+type htmlCell struct {
+	Text  string
+	Align Alignment
+	Color string
+}
+
+// htmlPage is a self-contained page with a single table, colors are inlined so that the
+// page keeps them when sent by email
+var htmlPage = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: system-ui, -apple-system, "Segoe UI", sans-serif; margin: 2rem; color: #212121; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #d0d0d0; padding: 0.35rem 0.7rem; }
+th { background: #f5f5f5; }
+.left { text-align: left; }
+.right { text-align: right; font-variant-numeric: tabular-nums; }
+.center { text-align: center; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<table>
+<thead>
+<tr>{{range .Header}}<th class="{{.Align}}">{{.Text}}</th>{{end}}</tr>
+</thead>
+<tbody>
+{{- range .Rows}}
+<tr>{{range .}}<td class="{{.Align}}"{{with .Color}} style="color: {{.}}; font-weight: bold"{{end}}>{{.Text}}</td>{{end}}</tr>
+{{- end}}
+</tbody>
+</table>
+</body>
+</html>
+`))
+
+// RenderHTML writes the table to w as a self-contained HTML page with the given title
+func (t *Table) RenderHTML(w io.Writer, title string) error {
+	page := struct {
+		Title  string
+		Header []htmlCell
+		Rows   [][]htmlCell
+	}{Title: title}
+
+	for _, col := range t.Columns {
+		page.Header = append(page.Header, htmlCell{Text: col.Name, Align: col.htmlAlign()})
+	}
+
+	for _, values := range t.rows {
+		row := make([]htmlCell, len(t.Columns))
+		for i, col := range t.Columns {
+			var value any
+			if i < len(values) {
+				value = values[i]
+			}
+			cell := col.cell(t.Theme, value)
+			row[i] = htmlCell{Text: cell.Text, Align: col.htmlAlign(), Color: cell.Color.CSS()}
+		}
+		page.Rows = append(page.Rows, row)
+	}
+
+	return htmlPage.Execute(w, page)
+}
+
+func (col Column) htmlAlign() Alignment {
+	if col.Align == "" {
+		return AlignLeft
+	}
+	return col.Align
+}
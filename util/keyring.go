@@ -0,0 +1,46 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zalando/go-keyring"
+)
+
+// keyringService is the service name suvctl credentials are filed under in the OS keyring
+const keyringService = "suvctl"
+
+// keyringStore keeps credentials in the OS keyring (Secret Service, macOS Keychain or
+// Windows Credential Manager)
+type keyringStore struct{}
+
+func (s *keyringStore) Get(key string) (string, error) {
+	secret, err := keyring.Get(keyringService, key)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", ErrCredentialNotFound
+	}
+	if err != nil {
+		return "", keyringError(err)
+	}
+
+	return secret, nil
+}
+
+func (s *keyringStore) Set(key, value string) error {
+	if err := keyring.Set(keyringService, key, value); err != nil {
+		return keyringError(err)
+	}
+	return nil
+}
+
+func (s *keyringStore) Delete(key string) error {
+	err := keyring.Delete(keyringService, key)
+	if err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return keyringError(err)
+	}
+	return nil
+}
+
+func keyringError(err error) error {
+	return fmt.Errorf("error accessing the OS keyring: %w (set credential_store to \"file\" if no keyring is available)", err)
+}
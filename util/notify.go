@@ -0,0 +1,371 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/spf13/viper"
+)
+
+// The types of notifier in the notify section of the config file
+const (
+	NotifyDesktop = "desktop"
+	NotifyEmail   = "email"
+	NotifyWebhook = "webhook"
+	NotifyCommand = "command"
+)
+
+// notifyTimeout is how long each notifier has to deliver a notification
+const notifyTimeout = 30 * time.Second
+
+// Notification tells that the grades changed
+type Notification struct {
+	Time    time.Time     `json:"time"`
+	Title   string        `json:"title"`
+	Message string        `json:"message"`
+	Changes []GradeChange `json:"changes"`
+}
+
+// NewNotification returns the notification of changes, with a message describing each of
+// them on a line
+func NewNotification(changes []GradeChange) Notification {
+	courses := map[int]bool{}
+	lines := make([]string, len(changes))
+	for i, change := range changes {
+		courses[change.CourseID] = true
+		lines[i] = change.describe(Colorizer{})
+	}
+
+	title := "The grades of 1 course changed in SUV"
+	if len(courses) != 1 {
+		title = fmt.Sprintf("The grades of %d courses changed in SUV", len(courses))
+	}
+
+	return Notification{
+		Time:    time.Now(),
+		Title:   title,
+		Message: strings.Join(lines, "\n"),
+		Changes: changes,
+	}
+}
+
+// Notifier delivers notifications somewhere the student will see them
+type Notifier interface {
+	Notify(ctx context.Context, notification Notification) error
+}
+
+// Notifiers delivers notifications through every notifier, each of them getting
+// notifyTimeout to do it, and reports the ones that failed
+type Notifiers []Notifier
+
+func (n Notifiers) Notify(ctx context.Context, notification Notification) error {
+	var errs []error
+	for _, notifier := range n {
+		ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+		if err := notifier.Notify(ctx, notification); err != nil {
+			errs = append(errs, err)
+		}
+		cancel()
+	}
+	return errors.Join(errs...)
+}
+
+// NotifierConfig is a notifier as set in the notify section of the config file, a list of
+// them. Type picks the notifier and the settings it reads.
+type NotifierConfig struct {
+	Type string `mapstructure:"type"`
+
+	// email: the SMTP server as host:port, the credentials to log in to it, if it needs
+	// them, and the addresses to send the email from and to
+	SMTP            string   `mapstructure:"smtp"`
+	Username        string   `mapstructure:"username"`
+	Password        string   `mapstructure:"password"`
+	PasswordCommand string   `mapstructure:"password_command"`
+	From            string   `mapstructure:"from"`
+	To              []string `mapstructure:"to"`
+
+	// webhook: the URL to post to, the body as a template run over the notification, or
+	// the one of a preset, and extra headers. ChatID is the Telegram chat to send to.
+	URL      string            `mapstructure:"url"`
+	Preset   string            `mapstructure:"preset"`
+	Template string            `mapstructure:"template"`
+	Headers  map[string]string `mapstructure:"headers"`
+	ChatID   string            `mapstructure:"chat_id"`
+
+	// command: the shell command to run with the notification as JSON on its stdin
+	Command string `mapstructure:"command"`
+}
+
+// webhookPresets are the bodies of the webhook presets for chat services
+var webhookPresets = map[string]string{
+	"slack":    `{"text": {{json .Text}}}`,
+	"discord":  `{"content": {{json .Text}}}`,
+	"telegram": `{"chat_id": {{json .ChatID}}, "text": {{json .Text}}}`,
+}
+
+// GetNotifiers returns the notifiers from the notify section of viper config
+func GetNotifiers() (Notifiers, error) {
+	var configs []NotifierConfig
+	if err := viper.UnmarshalKey("notify", &configs); err != nil {
+		return nil, Errorf(KindUsage, "invalid notify settings: %w", err)
+	}
+
+	notifiers := make(Notifiers, 0, len(configs))
+	for i, config := range configs {
+		notifier, err := config.notifier()
+		if err != nil {
+			return nil, Errorf(KindUsage, "invalid notifier %d: %w", i+1, err)
+		}
+		notifiers = append(notifiers, notifier)
+	}
+	return notifiers, nil
+}
+
+// notifier returns the notifier set by config, checking that it has what it needs
+func (config NotifierConfig) notifier() (Notifier, error) {
+	switch config.Type {
+	case NotifyDesktop:
+		return desktopNotifier{}, nil
+	case NotifyEmail:
+		if config.SMTP == "" || config.From == "" || len(config.To) == 0 {
+			return nil, errors.New("email notifiers need smtp, from and to")
+		}
+		if _, _, err := net.SplitHostPort(config.SMTP); err != nil {
+			return nil, fmt.Errorf("invalid smtp server %q, use host:port", config.SMTP)
+		}
+		return emailNotifier{config}, nil
+	case NotifyWebhook:
+		if config.URL == "" {
+			return nil, errors.New("webhook notifiers need a url")
+		}
+		source := config.Template
+		if config.Preset != "" {
+			var ok bool
+			if source, ok = webhookPresets[config.Preset]; !ok {
+				return nil, fmt.Errorf("unknown webhook preset %q (use slack, discord or telegram)", config.Preset)
+			}
+		}
+		var tmpl *template.Template
+		if source != "" {
+			var err error
+			if tmpl, err = template.New("webhook").Funcs(webhookFuncs()).Parse(source); err != nil {
+				return nil, fmt.Errorf("error parsing webhook template: %w", err)
+			}
+		}
+		return webhookNotifier{config, tmpl}, nil
+	case NotifyCommand:
+		if config.Command == "" {
+			return nil, errors.New("command notifiers need a command")
+		}
+		return commandNotifier{config.Command}, nil
+	default:
+		return nil, fmt.Errorf("unknown notifier type %q (use %s, %s, %s or %s)", config.Type, NotifyDesktop, NotifyEmail, NotifyWebhook, NotifyCommand)
+	}
+}
+
+// desktopNotifier shows notifications through the freedesktop notification server on the
+// session bus
+type desktopNotifier struct{}
+
+func (desktopNotifier) Notify(ctx context.Context, notification Notification) error {
+	conn, err := dbus.ConnectSessionBus(dbus.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("error connecting to the session bus: %w", err)
+	}
+	defer conn.Close()
+
+	server := conn.Object("org.freedesktop.Notifications", "/org/freedesktop/Notifications")
+	call := server.CallWithContext(ctx, "org.freedesktop.Notifications.Notify", 0,
+		"suvctl", uint32(0), "", notification.Title, notification.Message,
+		[]string{}, map[string]dbus.Variant{}, int32(-1))
+	if call.Err != nil {
+		return fmt.Errorf("error showing desktop notification: %w", call.Err)
+	}
+	return nil
+}
+
+// emailNotifier sends notifications by email, through an SMTP server that is talked to
+// over TLS when it offers STARTTLS
+type emailNotifier struct {
+	config NotifierConfig
+}
+
+func (n emailNotifier) Notify(ctx context.Context, notification Notification) error {
+	if err := n.send(ctx, n.message(notification)); err != nil {
+		return fmt.Errorf("error sending email notification: %w", err)
+	}
+	return nil
+}
+
+func (n emailNotifier) message(notification Notification) []byte {
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", n.config.From)
+	fmt.Fprintf(&message, "To: %s\r\n", strings.Join(n.config.To, ", "))
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", notification.Title))
+	fmt.Fprintf(&message, "Date: %s\r\n", notification.Time.Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	message.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	message.WriteString(strings.ReplaceAll(notification.Message, "\n", "\r\n"))
+	message.WriteString("\r\n")
+	return message.Bytes()
+}
+
+// send does what smtp.SendMail does, within the deadline of ctx
+func (n emailNotifier) send(ctx context.Context, message []byte) error {
+	host, _, _ := net.SplitHostPort(n.config.SMTP)
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", n.config.SMTP)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if n.config.Username != "" {
+		password := n.config.Password
+		if n.config.PasswordCommand != "" {
+			if password, err = RunPasswordCommand(n.config.PasswordCommand); err != nil {
+				return err
+			}
+		}
+		// PlainAuth refuses to send the password unencrypted to other hosts than localhost
+		if err := client.Auth(smtp.PlainAuth("", n.config.Username, password, host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(n.config.From); err != nil {
+		return err
+	}
+	for _, to := range n.config.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	data, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := data.Write(message); err != nil {
+		return err
+	}
+	if err := data.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// webhookNotifier posts notifications to a URL, as JSON or with a body of its own
+type webhookNotifier struct {
+	config   NotifierConfig
+	template *template.Template
+}
+
+// webhookData is what webhook templates run over: the notification, its title and message
+// together as Text, and the Telegram chat to send it to
+type webhookData struct {
+	Notification
+	Text   string
+	ChatID string
+}
+
+// webhookFuncs returns the helpers available to webhook templates: those of --output
+// template without colors, and json to quote a value as JSON
+func webhookFuncs() template.FuncMap {
+	funcs := templateFuncs(Colorizer{})
+	funcs["json"] = func(value any) (string, error) {
+		data, err := json.Marshal(value)
+		return string(data), err
+	}
+	return funcs
+}
+
+func (n webhookNotifier) Notify(ctx context.Context, notification Notification) error {
+	var body bytes.Buffer
+	if n.template == nil {
+		if err := json.NewEncoder(&body).Encode(notification); err != nil {
+			return err
+		}
+	} else {
+		data := webhookData{
+			Notification: notification,
+			Text:         notification.Title + "\n\n" + notification.Message,
+			ChatID:       n.config.ChatID,
+		}
+		if err := n.template.Execute(&body, data); err != nil {
+			return fmt.Errorf("error executing webhook template: %w", err)
+		}
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, n.config.URL, &body)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for name, value := range n.config.Headers {
+		request.Header.Set(name, value)
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("error posting webhook notification: %w", err)
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("error posting webhook notification: %s answered %s", n.config.URL, response.Status)
+	}
+	return nil
+}
+
+// commandNotifier runs a shell command with notifications as JSON on its stdin
+type commandNotifier struct {
+	command string
+}
+
+func (n commandNotifier) Notify(ctx context.Context, notification Notification) error {
+	data, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	cmd := shellCommand(ctx, n.command)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error running notify command: %w", err)
+	}
+	return nil
+}
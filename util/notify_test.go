@@ -0,0 +1,296 @@
+package util_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/patitolabs/suvctl/util"
+	"github.com/spf13/viper"
+)
+
+// notifiers returns the notifiers set by configs in the notify section
+func notifiers(t *testing.T, configs ...map[string]any) util.Notifiers {
+	t.Helper()
+
+	viper.Set("notify", configs)
+	t.Cleanup(func() { viper.Set("notify", nil) })
+
+	notifiers, err := util.GetNotifiers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return notifiers
+}
+
+func testNotification() util.Notification {
+	snapshots := historySnapshots()
+	return util.NewNotification(util.CompareGrades(snapshots[0].Courses, snapshots[1].Courses, util.GradeFilter{}, util.DefaultStatusRules))
+}
+
+func TestNotification(t *testing.T) {
+	notification := testNotification()
+
+	if notification.Title != "The grades of 1 course changed in SUV" {
+		t.Errorf("got title %q", notification.Title)
+	}
+	if notification.Message != "Unit 2 of course 3403 went from 0.00 to 15.00" {
+		t.Errorf("got message %q", notification.Message)
+	}
+}
+
+func TestNotifierConfig(t *testing.T) {
+	invalid := []map[string]any{
+		{"type": "pigeon"},
+		{"type": "email", "smtp": "localhost:25", "from": "suvctl@example.com"},
+		{"type": "email", "smtp": "localhost", "from": "suvctl@example.com", "to": []string{"me@example.com"}},
+		{"type": "webhook"},
+		{"type": "webhook", "url": "http://localhost", "preset": "myspace"},
+		{"type": "webhook", "url": "http://localhost", "template": "{{.Text"},
+		{"type": "command"},
+	}
+
+	for _, config := range invalid {
+		viper.Set("notify", []map[string]any{config})
+		if _, err := util.GetNotifiers(); err == nil {
+			t.Errorf("%v: want an error", config)
+		}
+	}
+	viper.Set("notify", nil)
+
+	if notifiers, err := util.GetNotifiers(); err != nil || len(notifiers) != 0 {
+		t.Errorf("no notifiers: got %v, %v", notifiers, err)
+	}
+}
+
+func TestCommandNotifier(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "notification.json")
+
+	err := notifiers(t, map[string]any{"type": "command", "command": "cat > " + out}).Notify(context.Background(), testNotification())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got util.Notification
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Changes) != 1 || got.Changes[0].CourseID != 3403 {
+		t.Fatalf("the command read %s", data)
+	}
+
+	err = notifiers(t, map[string]any{"type": "command", "command": "exit 3"}).Notify(context.Background(), testNotification())
+	if err == nil {
+		t.Fatal("failing command: want an error")
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	bodies := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "Bearer wrong" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		bodies <- string(body)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		config map[string]any
+		want   string
+	}{
+		{
+			map[string]any{"type": "webhook", "url": server.URL, "preset": "slack"},
+			`{"text": "The grades of 1 course changed in SUV\n\nUnit 2 of course 3403 went from 0.00 to 15.00"}`,
+		},
+		{
+			map[string]any{"type": "webhook", "url": server.URL, "preset": "telegram", "chat_id": "-100123"},
+			`{"chat_id": "-100123", "text": "The grades of 1 course changed in SUV\n\nUnit 2 of course 3403 went from 0.00 to 15.00"}`,
+		},
+		{
+			map[string]any{"type": "webhook", "url": server.URL, "template": `{{range .Changes}}{{.CourseID}} {{grade .To}}{{end}}`},
+			`3403 15.00`,
+		},
+	}
+
+	for _, tt := range tests {
+		if err := notifiers(t, tt.config).Notify(context.Background(), testNotification()); err != nil {
+			t.Fatal(err)
+		}
+		if got := <-bodies; got != tt.want {
+			t.Errorf("%v: posted %s, want %s", tt.config, got, tt.want)
+		}
+	}
+
+	// Without a template the notification is posted as JSON
+	if err := notifiers(t, map[string]any{"type": "webhook", "url": server.URL}).Notify(context.Background(), testNotification()); err != nil {
+		t.Fatal(err)
+	}
+	var got util.Notification
+	if err := json.Unmarshal([]byte(<-bodies), &got); err != nil || len(got.Changes) != 1 {
+		t.Errorf("posted %+v, %v", got, err)
+	}
+
+	err := notifiers(t, map[string]any{"type": "webhook", "url": server.URL, "headers": map[string]string{"Authorization": "Bearer wrong"}}).Notify(context.Background(), testNotification())
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("rejected webhook: got %v", err)
+	}
+}
+
+// serveSMTP accepts one SMTP session on listener, sending the message it receives to messages
+func serveSMTP(t *testing.T, listener net.Listener, messages chan<- string) {
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) { fmt.Fprint(conn, line+"\r\n") }
+
+	reply("220 localhost ESMTP stand-in")
+	var message strings.Builder
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.TrimSpace(line))
+
+		switch {
+		case strings.HasPrefix(command, "EHLO"):
+			reply("250-localhost")
+			reply("250 AUTH PLAIN")
+		case strings.HasPrefix(command, "AUTH PLAIN"):
+			reply("235 2.7.0 Authentication successful")
+		case strings.HasPrefix(command, "MAIL"), strings.HasPrefix(command, "RCPT"):
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil || line == ".\r\n" {
+					break
+				}
+				message.WriteString(line)
+			}
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 Bye")
+			messages <- message.String()
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func TestEmailNotifier(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	messages := make(chan string, 1)
+	go serveSMTP(t, listener, messages)
+
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	err = notifiers(t, map[string]any{
+		"type":     "email",
+		"smtp":     "localhost:" + port,
+		"username": "suvctl",
+		"password": "secret",
+		"from":     "suvctl@example.com",
+		"to":       []string{"me@example.com"},
+	}).Notify(context.Background(), testNotification())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := <-messages
+	for _, want := range []string{"To: me@example.com\r\n", "Subject: The grades of 1 course changed in SUV\r\n", "\r\n\r\nUnit 2 of course 3403 went from 0.00 to 15.00\r\n"} {
+		if !strings.Contains(message, want) {
+			t.Errorf("the message lacks %q:\n%s", want, message)
+		}
+	}
+}
+
+// notificationServer is a stand-in freedesktop notification server
+type notificationServer struct {
+	notifications chan [2]string
+}
+
+func (s notificationServer) Notify(app string, replaces uint32, icon, summary, body string, actions []string, hints map[string]dbus.Variant, timeout int32) (uint32, *dbus.Error) {
+	s.notifications <- [2]string{summary, body}
+	return 1, nil
+}
+
+func TestDesktopNotifier(t *testing.T) {
+	if _, err := exec.LookPath("dbus-daemon"); err != nil {
+		t.Skip("dbus-daemon is not installed")
+	}
+
+	// Start a session bus of our own for the stand-in notification server
+	daemon := exec.Command("dbus-daemon", "--session", "--nofork", "--print-address")
+	stdout, err := daemon.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := daemon.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		daemon.Process.Kill()
+		daemon.Wait()
+	}()
+
+	address, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", strings.TrimSpace(address))
+
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	server := notificationServer{make(chan [2]string, 1)}
+	if err := conn.Export(server, "/org/freedesktop/Notifications", "org.freedesktop.Notifications"); err != nil {
+		t.Fatal(err)
+	}
+	if reply, err := conn.RequestName("org.freedesktop.Notifications", dbus.NameFlagDoNotQueue); err != nil || reply != dbus.RequestNameReplyPrimaryOwner {
+		t.Fatalf("owning the notification server name: %v, %v", reply, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := notifiers(t, map[string]any{"type": "desktop"}).Notify(ctx, testNotification()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := <-server.notifications; got != [2]string{"The grades of 1 course changed in SUV", "Unit 2 of course 3403 went from 0.00 to 15.00"} {
+		t.Fatalf("the server got %q", got)
+	}
+}
@@ -3,792 +3,859 @@ package util
 import (
 	"encoding/json"
 	"fmt"
-	"os"
+	"io"
+	"reflect"
+	"slices"
 	"strings"
+	"time"
 
-	"github.com/patitolabs/gosuv2"
 	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
 )
 
 // OutputFormat represents the different output formats available
 type OutputFormat string
 
 const (
-	OutputText  OutputFormat = "text"
-	OutputTable OutputFormat = "table"
-	OutputJSON  OutputFormat = "json"
-	OutputRaw   OutputFormat = "raw"
+	OutputText     OutputFormat = "text"
+	OutputTable    OutputFormat = "table"
+	OutputJSON     OutputFormat = "json"
+	OutputRaw      OutputFormat = "raw"
+	OutputCSV      OutputFormat = "csv"
+	OutputTSV      OutputFormat = "tsv"
+	OutputYAML     OutputFormat = "yaml"
+	OutputNDJSON   OutputFormat = "ndjson"
+	OutputMarkdown OutputFormat = "markdown"
+	OutputHTML     OutputFormat = "html"
+
+	// OutputTemplate and OutputTemplateFile take the template after a "=", as in
+	// --output template={{.CourseName}}
+	OutputTemplate     OutputFormat = "template"
+	OutputTemplateFile OutputFormat = "template-file"
 )
 
-// GetOutputFormat returns the current output format from viper config
-func GetOutputFormat() OutputFormat {
-	format := viper.GetString("output")
-	switch format {
-	case "text":
-		return OutputText
-	case "json":
-		return OutputJSON
-	case "raw":
-		return OutputRaw
-	default:
-		return OutputTable
-	}
-}
-
-// GradeData represents structured grade data for formatting
-type GradeData struct {
-	CourseID     int     `json:"course_id"`
-	CourseName   string  `json:"course_name"`
-	Attempt      int     `json:"attempt"`
-	Average1     float32 `json:"average_1,omitempty"`
-	Average2     float32 `json:"average_2,omitempty"`
-	Average3     float32 `json:"average_3,omitempty"`
-	Average4     float32 `json:"average_4,omitempty"`
-	Average5     float32 `json:"average_5,omitempty"`
-	Average6     float32 `json:"average_6,omitempty"`
-	Substitute   float32 `json:"substitute,omitempty"`
-	Average      float32 `json:"average,omitempty"`
-	Postponed    float32 `json:"postponed,omitempty"`
-	FinalAverage float32 `json:"final_average,omitempty"`
-	Disabled     bool    `json:"disabled"`
-	FinalStatus  string  `json:"final_status"`
-}
-
-// StudentData represents structured student data for formatting
-type StudentData struct {
-	StudentID   string `json:"student_id"`
-	StudentName string `json:"student_name"`
-	DNI         string `json:"dni"`
-}
-
-// ProfessorData represents structured professor data for formatting
-type ProfessorData struct {
-	Code          string `json:"code"`
-	ProfessorName string `json:"professor_name"`
-	DNI           string `json:"dni"`
-	WorkerID      string `json:"worker_id"`
-}
-
-// OutputGrades outputs grades in the specified format
-func OutputGrades(grades []gosuv2.SuvCurrentCourseGrades) {
-	format := GetOutputFormat()
-	switch format {
-	case OutputJSON:
-		outputGradesJSON(grades)
-	case OutputRaw:
-		outputGradesRaw(grades)
-	case OutputTable:
-		outputGradesTable(grades)
+// OutputOptions tell how results are printed. The zero value prints tables without colors.
+type OutputOptions struct {
+	Format OutputFormat
+	// Template is the template of the template format, or the file holding it for the
+	// template-file format
+	Template string
+	// Fields and JSONPath narrow the results down to some of their fields, as --fields
+	// and --jsonpath. They cannot be used together.
+	Fields   []string
+	JSONPath string
+	// Border is the border style of table output, rounded when empty
+	Border BorderStyle
+	// Delimiter separates the fields of csv output, a comma when 0. NoHeader leaves out
+	// the header of csv and tsv output.
+	Delimiter rune
+	NoHeader  bool
+	// Color tells when output is colored, auto when empty, in the colors of Theme
+	Color ColorMode
+	Theme Theme
+}
+
+// GetOutputOptions returns the output options from viper config
+func GetOutputOptions() (OutputOptions, error) {
+	var opts OutputOptions
+	var err error
+
+	if opts.Format, opts.Template, err = parseOutputFormat(viper.GetString("output")); err != nil {
+		return OutputOptions{}, err
+	}
+	opts.Fields = parseFields(viper.GetStringSlice("fields"))
+	opts.JSONPath = viper.GetString("jsonpath")
+	opts.NoHeader = viper.GetBool("no_header")
+
+	if opts.Border, err = parseTableBorder(viper.GetString("table_border")); err != nil {
+		return OutputOptions{}, err
+	}
+	if opts.Delimiter, err = parseDelimiter(viper.GetString("delimiter")); err != nil {
+		return OutputOptions{}, err
+	}
+	if opts.Color, err = GetColorMode(); err != nil {
+		return OutputOptions{}, err
+	}
+	if opts.Theme, err = GetTheme(); err != nil {
+		return OutputOptions{}, err
+	}
+
+	return opts, nil
+}
+
+// parseOutputFormat returns the format named by output, table when it is empty, and the
+// template that follows a "=", as in template={{.CourseName}}
+func parseOutputFormat(output string) (OutputFormat, string, error) {
+	format, template, _ := strings.Cut(output, "=")
+	switch OutputFormat(format) {
+	case "", OutputTable:
+		return OutputTable, "", nil
+	case OutputText, OutputJSON, OutputRaw, OutputCSV, OutputTSV, OutputYAML, OutputNDJSON, OutputMarkdown, OutputHTML:
+		return OutputFormat(format), "", nil
+	case OutputTemplate, OutputTemplateFile:
+		return OutputFormat(format), template, nil
 	default:
-		outputGradesText(grades)
+		return "", "", Errorf(KindUsage, "unknown output format %q (use text, table, json, raw, yaml, ndjson, markdown, html, csv, tsv, template=TEXT or template-file=PATH)", output)
 	}
 }
 
-// OutputStudents outputs students in the specified format
-func OutputStudents(students []gosuv2.StudentBasicResponse) {
-	format := GetOutputFormat()
-	switch format {
-	case OutputJSON:
-		outputStudentsJSON(students)
-	case OutputRaw:
-		outputStudentsRaw(students)
-	case OutputTable:
-		outputStudentsTable(students)
-	default:
-		outputStudentsText(students)
+// withDefaults fills in the format and delimiter left empty
+func (o OutputOptions) withDefaults() OutputOptions {
+	if o.Format == "" {
+		o.Format = OutputTable
+	}
+	if o.Delimiter == 0 {
+		o.Delimiter = ','
 	}
+	return o
 }
 
-// OutputProfessors outputs professors in the specified format
-func OutputProfessors(professors []gosuv2.ProfessorBasicResponse) {
-	format := GetOutputFormat()
-	switch format {
-	case OutputJSON:
-		outputProfessorsJSON(professors)
-	case OutputRaw:
-		outputProfessorsRaw(professors)
-	case OutputTable:
-		outputProfessorsTable(professors)
-	default:
-		outputProfessorsText(professors)
+// colorizer returns the Colorizer of text output written to w
+func (o OutputOptions) colorizer(w io.Writer) Colorizer {
+	return NewColorizer(w, o.Color, o.Theme)
+}
+
+// OutputGrades writes grades to w as opts tell
+func OutputGrades(w io.Writer, grades []GradeData, opts OutputOptions) error {
+	opts = opts.withDefaults()
+	if handled, err := outputData(w, opts, grades); handled {
+		return err
 	}
+
+	if isTableFormat(opts.Format) {
+		return outputGradesTable(w, grades, opts)
+	}
+	outputGradesText(w, grades, opts.colorizer(w))
+	return nil
 }
 
-func outputGradesJSON(grades []gosuv2.SuvCurrentCourseGrades) {
-	var gradeData []GradeData
-	for _, grade := range grades {
-		finalStatus := determineFinalStatus(grade)
-		data := GradeData{
-			CourseID:     grade.CourseID,
-			CourseName:   grade.CourseName,
-			Attempt:      grade.Attempt,
-			Average1:     grade.Average1,
-			Average2:     grade.Average2,
-			Average3:     grade.Average3,
-			Average4:     grade.Average4,
-			Average5:     grade.Average5,
-			Average6:     grade.Average6,
-			Substitute:   grade.Substitute,
-			Average:      grade.Average,
-			Postponed:    grade.Postponed,
-			FinalAverage: grade.FinalAverage,
-			Disabled:     grade.Disabled,
-			FinalStatus:  finalStatus,
-		}
-		gradeData = append(gradeData, data)
+// OutputStudents writes students to w as opts tell
+func OutputStudents(w io.Writer, students []StudentData, opts OutputOptions) error {
+	opts = opts.withDefaults()
+	if handled, err := outputData(w, opts, students); handled {
+		return err
 	}
 
-	output, err := json.MarshalIndent(gradeData, "", "  ")
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error marshaling JSON: %v\n", err)
-		os.Exit(1)
+	if isTableFormat(opts.Format) {
+		return outputStudentsTable(w, students, opts)
 	}
-	fmt.Println(string(output))
+	outputStudentsText(w, students)
+	return nil
 }
 
-func outputGradesTable(grades []gosuv2.SuvCurrentCourseGrades) {
-	if len(grades) == 0 {
-		fmt.Println("No courses found.")
-		return
+// OutputProfessors writes professors to w as opts tell
+func OutputProfessors(w io.Writer, professors []ProfessorData, opts OutputOptions) error {
+	opts = opts.withDefaults()
+	if handled, err := outputData(w, opts, professors); handled {
+		return err
 	}
 
-	// Analyze which columns have data
-	columns := analyzeGradeColumns(grades)
+	if isTableFormat(opts.Format) {
+		return outputProfessorsTable(w, professors, opts)
+	}
+	outputProfessorsText(w, professors)
+	return nil
+}
 
-	// Build the table
-	printGradeTableHeader(columns)
-	printGradeTableSeparator(columns)
+// OutputProjections writes grade projections to w as opts tell
+func OutputProjections(w io.Writer, projections []Projection, opts OutputOptions) error {
+	opts = opts.withDefaults()
+	if handled, err := outputData(w, opts, projections); handled {
+		return err
+	}
 
-	for _, grade := range grades {
-		printGradeTableRow(grade, columns)
+	if isTableFormat(opts.Format) {
+		return outputProjectionsTable(w, projections, opts)
+	}
+	outputProjectionsText(w, projections, opts.colorizer(w))
+	return nil
+}
+
+// OutputHistory writes the timeline of grade history entries to w as opts tell
+func OutputHistory(w io.Writer, entries []HistoryEntry, opts OutputOptions) error {
+	opts = opts.withDefaults()
+	if handled, err := outputData(w, opts, entries); handled {
+		return err
 	}
 
-	printGradeTableFooter(columns)
+	if isTableFormat(opts.Format) {
+		return outputHistoryTable(w, entries, opts)
+	}
+	outputHistoryText(w, entries, opts.colorizer(w))
+	return nil
 }
 
-// Column represents a table column with its properties
-type Column struct {
-	Name    string
-	Width   int
-	Align   string // "left", "right", "center"
-	HasData bool
+// OutputSnapshots writes the snapshots of the grade history to w as opts tell
+func OutputSnapshots(w io.Writer, snapshots []SnapshotInfo, opts OutputOptions) error {
+	opts = opts.withDefaults()
+	if handled, err := outputData(w, opts, snapshots); handled {
+		return err
+	}
+
+	if isTableFormat(opts.Format) {
+		return outputSnapshotsTable(w, snapshots, opts)
+	}
+	outputSnapshotsText(w, snapshots)
+	return nil
 }
 
-func analyzeGradeColumns(grades []gosuv2.SuvCurrentCourseGrades) []Column {
-	// Initialize all possible columns
-	columns := []Column{
-		{"Course", 9, "right", true},
-		{"Course Name", 36, "left", true},
-		{"Attempt", 9, "right", true},
-		{"Unit 1", 9, "right", false},
-		{"Unit 2", 9, "right", false},
-		{"Unit 3", 9, "right", false},
-		{"Unit 4", 9, "right", false},
-		{"Unit 5", 9, "right", false},
-		{"Unit 6", 9, "right", false},
-		{"Subst", 9, "right", false},
-		{"Failed", 9, "right", false},
-		{"Average", 9, "right", false},
-		{"Final Avg", 9, "right", false},
-		{"Status", 10, "center", true},
+// OutputChanges writes the changes between two snapshots of the grades to w as opts tell
+func OutputChanges(w io.Writer, changes []GradeChange, opts OutputOptions) error {
+	opts = opts.withDefaults()
+	if handled, err := outputData(w, opts, changes); handled {
+		return err
 	}
 
-	// Check which columns have data
-	for _, grade := range grades {
-		if grade.Average1 != 0 {
-			columns[3].HasData = true
-		}
-		if grade.Average2 != 0 {
-			columns[4].HasData = true
-		}
-		if grade.Average3 != 0 {
-			columns[5].HasData = true
-		}
-		if grade.Average4 != 0 {
-			columns[6].HasData = true
-		}
-		if grade.Average5 != 0 {
-			columns[7].HasData = true
-		}
-		if grade.Average6 != 0 {
-			columns[8].HasData = true
-		}
-		if grade.Substitute != 0 {
-			columns[9].HasData = true
-		}
-		if grade.Postponed != 0 {
-			columns[10].HasData = true
+	if isTableFormat(opts.Format) {
+		return outputChangesTable(w, changes, opts)
+	}
+	outputChangesText(w, changes, opts.colorizer(w))
+	return nil
+}
+
+// OutputSummary writes the summary of a period to w as opts tell
+func OutputSummary(w io.Writer, summary Summary, opts OutputOptions) error {
+	opts = opts.withDefaults()
+
+	// Print the summary as an object rather than a list of one, unless fields were selected
+	if len(opts.Fields) == 0 && opts.JSONPath == "" {
+		switch opts.Format {
+		case OutputJSON:
+			return outputJSON(w, summary)
+		case OutputRaw:
+			return outputRaw(w, summary)
+		case OutputYAML:
+			return outputYAML(w, summary)
 		}
-		if grade.Average != 0 {
-			columns[11].HasData = true
+	}
+
+	if handled, err := outputData(w, opts, []Summary{summary}); handled {
+		return err
+	}
+
+	if isTableFormat(opts.Format) {
+		return outputSummaryTable(w, summary, opts)
+	}
+	outputSummaryText(w, summary, opts.colorizer(w))
+	return nil
+}
+
+// outputData prints data in the formats shared by every kind of result, applying --fields
+// and --jsonpath. It reports false for the table and text formats, which each kind of
+// result prints its own way.
+func outputData[T any](w io.Writer, opts OutputOptions, data []T) (bool, error) {
+	selected, err := selectRecords(data, opts.Fields, opts.JSONPath)
+	if err != nil {
+		return true, err
+	}
+
+	format := opts.Format
+	switch format {
+	case OutputJSON, OutputRaw, OutputYAML, OutputNDJSON:
+		var value any = data
+		if selected != nil {
+			if value, err = selected.jsonValue(); err != nil {
+				return true, err
+			}
 		}
-		if grade.FinalAverage != 0 {
-			columns[12].HasData = true
+
+		switch format {
+		case OutputJSON:
+			return true, outputJSON(w, value)
+		case OutputRaw:
+			return true, outputRaw(w, value)
+		case OutputYAML:
+			return true, outputYAML(w, value)
+		default:
+			return true, outputNDJSON(w, value)
+		}
+	case OutputCSV, OutputTSV:
+		r := newRecords(data)
+		if selected != nil {
+			r = selected.records
+		}
+		if format == OutputCSV {
+			return true, outputCSV(w, r, opts)
+		}
+		return true, outputTSV(w, r, opts)
+	case OutputTemplate, OutputTemplateFile:
+		if selected != nil {
+			return true, Errorf(KindUsage, "--fields and --jsonpath are not supported by %s output", format)
+		}
+		return true, outputTemplate(w, data, opts)
+	case OutputTable, OutputMarkdown, OutputHTML:
+		// --fields keeps the columns of each kind of table, --jsonpath results get a plain one
+		if selected != nil && len(opts.Fields) == 0 {
+			return true, outputRecordsTable(w, selected.records, opts)
+		}
+		return false, nil
+	default:
+		if selected != nil {
+			return true, Errorf(KindUsage, "--fields and --jsonpath are not supported by %s output", format)
 		}
+		return false, nil
 	}
+}
 
-	// Filter to only include columns with data
-	var activeColumns []Column
-	for _, col := range columns {
-		if col.HasData {
-			activeColumns = append(activeColumns, col)
-		}
+// outputJSON prints data as indented JSON
+func outputJSON(w io.Writer, data any) error {
+	output, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling JSON: %w", err)
 	}
+	fmt.Fprintln(w, string(output))
+	return nil
+}
 
-	return activeColumns
+// fieldColumn is a table column showing a field of a result
+type fieldColumn[T any] struct {
+	Column
+	// field is the name of the field in JSON, as used by --fields
+	field string
+	value func(item T) any
+	show  columnVisibility
 }
 
-func printGradeTableHeader(columns []Column) {
-	// Top border
-	fmt.Print("╭")
-	for i, col := range columns {
-		fmt.Print(strings.Repeat("─", col.Width))
-		if i < len(columns)-1 {
-			fmt.Print("┬")
+// columnVisibility tells when a table column is shown if --fields does not choose them
+type columnVisibility int
+
+const (
+	showAlways    columnVisibility = iota
+	showWithData                   // when some item has a value for it
+	showOnRequest                  // only when chosen with --fields
+)
+
+var gradeColumns = []fieldColumn[GradeData]{
+	{Column{Name: "Course", Align: AlignRight}, "course_id", func(g GradeData) any { return g.CourseID }, showAlways},
+	{Column{Name: "Course Name", MaxWidth: 40}, "course_name", func(g GradeData) any { return g.CourseName }, showAlways},
+	{Column{Name: "Attempt", Align: AlignRight}, "attempt", func(g GradeData) any { return g.Attempt }, showAlways},
+	{gradeValueColumn("Unit 1"), "average_1", func(g GradeData) any { return g.Average1 }, showWithData},
+	{gradeValueColumn("Unit 2"), "average_2", func(g GradeData) any { return g.Average2 }, showWithData},
+	{gradeValueColumn("Unit 3"), "average_3", func(g GradeData) any { return g.Average3 }, showWithData},
+	{gradeValueColumn("Unit 4"), "average_4", func(g GradeData) any { return g.Average4 }, showWithData},
+	{gradeValueColumn("Unit 5"), "average_5", func(g GradeData) any { return g.Average5 }, showWithData},
+	{gradeValueColumn("Unit 6"), "average_6", func(g GradeData) any { return g.Average6 }, showWithData},
+	{gradeValueColumn("Subst"), "substitute", func(g GradeData) any { return g.Substitute }, showWithData},
+	{gradeValueColumn("Failed"), "postponed", func(g GradeData) any { return g.Postponed }, showWithData},
+	{gradeValueColumn("Average"), "average", func(g GradeData) any { return g.Average }, showWithData},
+	{gradeValueColumn("Final Avg"), "final_average", func(g GradeData) any { return g.FinalAverage }, showWithData},
+	{Column{Name: "Disabled", Align: AlignCenter}, "disabled", func(g GradeData) any { return g.Disabled }, showOnRequest},
+	{Column{Name: "Status", Align: AlignCenter, Color: statusColor}, "final_status", func(g GradeData) any { return g.FinalStatus }, showAlways},
+	{Column{Name: "Reason", MaxWidth: 40, Wrap: true}, "status_reason", func(g GradeData) any { return g.StatusReason }, showOnRequest},
+}
+
+var projectionColumns = []fieldColumn[Projection]{
+	{Column{Name: "Course", Align: AlignRight}, "course_id", func(p Projection) any { return p.CourseID }, showAlways},
+	{Column{Name: "Course Name", MaxWidth: 40}, "course_name", func(p Projection) any { return p.CourseName }, showAlways},
+	{gradeValueColumn("Average"), "average", func(p Projection) any { return p.Average }, showAlways},
+	{Column{Name: "Remaining", Align: AlignCenter, Format: formatRecordValue}, "remaining_units", func(p Projection) any { return p.RemainingUnits }, showAlways},
+	{Column{Name: "Needed", Align: AlignRight, Format: formatGradeValue, Color: neededColor}, "needed", func(p Projection) any { return p.Needed }, showAlways},
+	{Column{Name: "Plan", Wrap: true}, "plan", func(p Projection) any { return p.Plan }, showAlways},
+	{Column{Name: "Substitute", Align: AlignRight, Format: formatGradeValue, Color: neededColor}, "substitute_needed", func(p Projection) any { return p.SubstituteNeeded }, showWithData},
+	{Column{Name: "Replaces", Align: AlignCenter, Format: formatUnitValue}, "substitute_unit", func(p Projection) any { return p.SubstituteUnit }, showWithData},
+	{Column{Name: "Reachable", Align: AlignCenter}, "reachable", func(p Projection) any { return p.Reachable }, showOnRequest},
+}
+
+var historyColumns = []fieldColumn[HistoryEntry]{
+	{Column{Name: "Time", Format: formatHistoryTime}, "time", func(e HistoryEntry) any { return e.Time }, showAlways},
+	{Column{Name: "Course", Align: AlignRight}, "course_id", func(e HistoryEntry) any { return e.CourseID }, showAlways},
+	{Column{Name: "Course Name", MaxWidth: 40}, "course_name", func(e HistoryEntry) any { return e.CourseName }, showAlways},
+	{Column{Name: "Field", Format: func(value any) string { return fieldLabel(value.(string)) }}, "field", func(e HistoryEntry) any { return e.Field }, showAlways},
+	{Column{Name: "From", Align: AlignRight, Format: formatHistoryValue}, "from", func(e HistoryEntry) any { return e.From }, showAlways},
+	{Column{Name: "To", Align: AlignRight, Format: formatHistoryValue, Color: historyValueColor}, "to", func(e HistoryEntry) any { return e.To }, showAlways},
+}
+
+var snapshotColumns = []fieldColumn[SnapshotInfo]{
+	{Column{Name: "ID", Align: AlignRight}, "id", func(s SnapshotInfo) any { return s.ID }, showAlways},
+	{Column{Name: "Fetched", Format: formatHistoryTime}, "fetched_at", func(s SnapshotInfo) any { return s.FetchedAt }, showAlways},
+	{Column{Name: "Period"}, "semester", func(s SnapshotInfo) any { return s.Semester }, showAlways},
+	{Column{Name: "Courses", Align: AlignRight}, "courses", func(s SnapshotInfo) any { return s.Courses }, showAlways},
+}
+
+var changeColumns = []fieldColumn[GradeChange]{
+	{Column{Name: "Course", Align: AlignRight}, "course_id", func(c GradeChange) any { return c.CourseID }, showAlways},
+	{Column{Name: "Course Name", MaxWidth: 40}, "course_name", func(c GradeChange) any { return c.CourseName }, showAlways},
+	{Column{Name: "Change", Align: AlignCenter}, "change", func(c GradeChange) any { return c.Change }, showAlways},
+	{Column{Name: "Field", Format: func(value any) string { return fieldLabel(value.(string)) }}, "field", func(c GradeChange) any { return c.Field }, showAlways},
+	{Column{Name: "From", Align: AlignRight, Format: formatChangeValue}, "from", func(c GradeChange) any { return c.From }, showAlways},
+	{Column{Name: "To", Align: AlignRight, Format: formatChangeValue, Color: historyValueColor}, "to", func(c GradeChange) any { return c.To }, showAlways},
+}
+
+var studentColumns = []fieldColumn[StudentData]{
+	{Column{Name: "Student ID"}, "student_id", func(s StudentData) any { return s.StudentID }, showAlways},
+	{Column{Name: "Student Name", Wrap: true}, "student_name", func(s StudentData) any { return s.StudentName }, showAlways},
+	{Column{Name: "DNI"}, "dni", func(s StudentData) any { return s.DNI }, showAlways},
+}
+
+var professorColumns = []fieldColumn[ProfessorData]{
+	{Column{Name: "Code"}, "code", func(p ProfessorData) any { return p.Code }, showAlways},
+	{Column{Name: "Professor Name", Wrap: true}, "professor_name", func(p ProfessorData) any { return p.ProfessorName }, showAlways},
+	{Column{Name: "DNI"}, "dni", func(p ProfessorData) any { return p.DNI }, showAlways},
+	{Column{Name: "Worker ID"}, "worker_id", func(p ProfessorData) any { return p.WorkerID }, showAlways},
+}
+
+func gradeValueColumn(name string) Column {
+	return Column{Name: name, Align: AlignRight, Format: formatGradeValue, Color: gradeValueColor}
+}
+
+// selectColumns returns the columns of the given fields, in their order, or else the
+// columns shown by default
+func selectColumns[T any](columns []fieldColumn[T], data []T, fields []string) ([]fieldColumn[T], error) {
+	if len(fields) > 0 {
+		selected := make([]fieldColumn[T], 0, len(fields))
+		for _, field := range fields {
+			index := slices.IndexFunc(columns, func(col fieldColumn[T]) bool { return col.field == field })
+			if index < 0 {
+				return nil, newRecords(data).unknownField(field)
+			}
+			selected = append(selected, columns[index])
 		}
+		return selected, nil
 	}
-	fmt.Println("╮")
 
-	// Header row
-	fmt.Print("│")
+	var selected []fieldColumn[T]
 	for _, col := range columns {
-		padding := col.Width - len(col.Name)
-		switch col.Align {
-		case "center":
-			leftPad := padding / 2
-			rightPad := padding - leftPad
-			fmt.Printf("%s%s%s│", strings.Repeat(" ", leftPad), col.Name, strings.Repeat(" ", rightPad))
-		case "right":
-			fmt.Printf("%s%s │", strings.Repeat(" ", padding-1), col.Name)
-		default: // left
-			fmt.Printf(" %-*s│", col.Width-1, col.Name)
+		shown := col.show == showAlways
+		if col.show == showWithData {
+			shown = slices.ContainsFunc(data, func(item T) bool {
+				return !reflect.ValueOf(col.value(item)).IsZero()
+			})
 		}
-	}
-	fmt.Println()
-}
-
-func printGradeTableSeparator(columns []Column) {
-	fmt.Print("├")
-	for i, col := range columns {
-		fmt.Print(strings.Repeat("─", col.Width))
-		if i < len(columns)-1 {
-			fmt.Print("┼")
+		if shown {
+			selected = append(selected, col)
 		}
 	}
-	fmt.Println("┤")
+	return selected, nil
 }
 
-func printGradeTableFooter(columns []Column) {
-	fmt.Print("╰")
-	for i, col := range columns {
-		fmt.Print(strings.Repeat("─", col.Width))
-		if i < len(columns)-1 {
-			fmt.Print("┴")
+// newFieldTable returns a table with the given columns and a row per item
+func newFieldTable[T any](w io.Writer, opts OutputOptions, columns []fieldColumn[T], data []T) *Table {
+	table := newOutputTable(w, opts)
+
+	for _, col := range columns {
+		table.Columns = append(table.Columns, col.Column)
+	}
+	for _, item := range data {
+		row := make([]any, len(columns))
+		for i, col := range columns {
+			row[i] = col.value(item)
 		}
+		table.AddRow(row...)
 	}
-	fmt.Println("╯")
-}
 
-func printGradeTableRow(grade gosuv2.SuvCurrentCourseGrades, columns []Column) {
-	finalStatus := determineFinalStatus(grade)
+	return table
+}
 
-	// Truncate course name if too long
-	courseName := grade.CourseName
-	maxNameLen := 34 // Account for padding
-	if len(courseName) > maxNameLen {
-		courseName = courseName[:maxNameLen-3] + "..."
+func outputGradesTable(w io.Writer, grades []GradeData, opts OutputOptions) error {
+	if len(grades) == 0 && opts.Format != OutputHTML {
+		fmt.Fprintln(w, "No courses found.")
+		return nil
 	}
 
-	fmt.Print("│")
+	// Analyze which columns have data
+	columns, err := selectColumns(gradeColumns, grades, opts.Fields)
+	if err != nil {
+		return err
+	}
 
+	table := newOutputTable(w, opts)
 	for _, col := range columns {
-		var content string
-		var useColor bool
-		var colorCode string
-
-		switch col.Name {
-		case "Course":
-			content = fmt.Sprintf("%d", grade.CourseID)
-		case "Course Name":
-			content = courseName
-		case "Attempt":
-			content = fmt.Sprintf("%d", grade.Attempt)
-		case "Unit 1":
-			content, useColor, colorCode = formatGradeValue(grade.Average1)
-		case "Unit 2":
-			content, useColor, colorCode = formatGradeValue(grade.Average2)
-		case "Unit 3":
-			content, useColor, colorCode = formatGradeValue(grade.Average3)
-		case "Unit 4":
-			content, useColor, colorCode = formatGradeValue(grade.Average4)
-		case "Unit 5":
-			content, useColor, colorCode = formatGradeValue(grade.Average5)
-		case "Unit 6":
-			content, useColor, colorCode = formatGradeValue(grade.Average6)
-		case "Subst":
-			content, useColor, colorCode = formatGradeValue(grade.Substitute)
-		case "Failed":
-			content, useColor, colorCode = formatGradeValue(grade.Postponed)
-		case "Average":
-			content, useColor, colorCode = formatGradeValue(grade.Average)
-		case "Final Avg":
-			content, useColor, colorCode = formatGradeValue(grade.FinalAverage)
-		case "Status":
-			content = finalStatus
-			useColor = true
-			colorCode = getStatusColor(finalStatus)
-		}
-
-		// Apply formatting based on column alignment
-		padding := col.Width - len(content)
-		if useColor {
-			switch col.Align {
-			case "center":
-				leftPad := padding / 2
-				rightPad := padding - leftPad
-				fmt.Printf("%s%s%s%s\033[0m│", strings.Repeat(" ", leftPad), colorCode, content, strings.Repeat(" ", rightPad))
-			case "right":
-				fmt.Printf("%s%s%s\033[0m │", strings.Repeat(" ", padding-1), colorCode, content)
-			default: // left
-				fmt.Printf(" %s%s\033[0m%s│", colorCode, content, strings.Repeat(" ", padding-1))
-			}
-		} else {
-			switch col.Align {
-			case "center":
-				leftPad := padding / 2
-				rightPad := padding - leftPad
-				fmt.Printf("%s%s%s│", strings.Repeat(" ", leftPad), content, strings.Repeat(" ", rightPad))
-			case "right":
-				fmt.Printf("%s%s │", strings.Repeat(" ", padding-1), content)
-			default: // left
-				fmt.Printf(" %-*s│", col.Width-1, content)
-			}
-		}
+		table.Columns = append(table.Columns, col.Column)
 	}
-	fmt.Println()
 
-	// Show warning for disqualified students
-	if grade.Disabled {
-		fmt.Print("│")
+	for _, grade := range grades {
+		row := make([]any, len(columns))
 		for i, col := range columns {
-			if i == 1 { // Course Name column
-				warning := "\033[31mWARNING: Student disqualified\033[0m"
-				fmt.Printf(" %-*s│", col.Width-1, warning)
-			} else {
-				fmt.Printf("%s│", strings.Repeat(" ", col.Width))
+			row[i] = col.value(grade)
+		}
+		table.AddRow(row...)
+
+		// Show warning for disqualified students
+		if grade.Disabled {
+			warning := make([]any, len(columns))
+			for i, col := range columns {
+				if col.field == "course_name" {
+					warning[i] = tableCell{"WARNING: Student disqualified", opts.Theme.Warning}
+				}
 			}
+			table.AddRow(warning...)
 		}
-		fmt.Println()
 	}
+
+	return renderTable(w, table, opts.Format, "Grades")
 }
 
-func formatGradeValue(grade float32) (string, bool, string) {
+func formatGradeValue(value any) string {
+	grade, _ := value.(float32)
 	if grade == 0 {
-		return "-", false, ""
+		return "-"
 	}
+	return fmt.Sprintf("%.2f", grade)
+}
 
-	content := fmt.Sprintf("%.2f", grade)
-	useColor := true
-	colorCode := getGradeColor(grade)
+func gradeValueColor(theme Theme, value any) Color {
+	grade, _ := value.(float32)
+	return gradeValueTheme(theme, grade)
+}
 
-	return content, useColor, colorCode
+func statusColor(theme Theme, value any) Color {
+	status, _ := value.(string)
+	return theme.StatusColor(status)
 }
 
-func outputStudentsJSON(students []gosuv2.StudentBasicResponse) {
-	var studentData []StudentData
-	for _, student := range students {
-		data := StudentData{
-			StudentID:   student.StudentID,
-			StudentName: student.StudentName,
-			DNI:         student.DNI,
-		}
-		studentData = append(studentData, data)
+// neededColor colors a needed grade out of reach as a failed course
+func neededColor(theme Theme, value any) Color {
+	grade, _ := value.(float32)
+	if grade > maxGrade {
+		return theme.Failed
 	}
+	return ColorDefault
+}
 
-	output, err := json.MarshalIndent(studentData, "", "  ")
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error marshaling JSON: %v\n", err)
-		os.Exit(1)
+func outputProjectionsTable(w io.Writer, projections []Projection, opts OutputOptions) error {
+	if len(projections) == 0 && opts.Format != OutputHTML {
+		fmt.Fprintln(w, "No courses found.")
+		return nil
 	}
-	fmt.Println(string(output))
-}
 
-func outputStudentsTable(students []gosuv2.StudentBasicResponse) {
-	if len(students) == 0 {
-		fmt.Println("No students found")
-		return
+	columns, err := selectColumns(projectionColumns, projections, opts.Fields)
+	if err != nil {
+		return err
 	}
+	table := newFieldTable(w, opts, columns, projections)
+
+	return renderTable(w, table, opts.Format, "What if")
+}
 
-	// Calculate column widths based on content
-	columns := []Column{
-		{"Student ID", 13, "left", true},
-		{"Student Name", 36, "left", true},
-		{"DNI", 13, "left", true},
+// formatUnitValue shows the number of a unit, "-" when there is none
+func formatUnitValue(value any) string {
+	if unit, _ := value.(int); unit != 0 {
+		return fmt.Sprint(unit)
 	}
+	return "-"
+}
 
-	// Adjust column widths based on actual content
-	maxIDLen := len("Student ID")
-	maxNameLen := len("Student Name")
-	maxDNILen := len("DNI")
+// formatHistoryTime shows when a change was seen in the local time zone
+func formatHistoryTime(value any) string {
+	t, _ := value.(time.Time)
+	return t.Local().Format("2006-01-02 15:04")
+}
 
-	for _, student := range students {
-		if len(student.StudentID) > maxIDLen {
-			maxIDLen = len(student.StudentID)
-		}
-		if len(student.StudentName) > maxNameLen {
-			maxNameLen = len(student.StudentName)
-		}
-		if len(student.DNI) > maxDNILen {
-			maxDNILen = len(student.DNI)
-		}
+// formatHistoryValue shows a value of the grade history as the grades and statuses are
+// shown elsewhere, "-" when it had none
+func formatHistoryValue(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return "-"
+	case float32:
+		return formatGradeValue(value)
+	default:
+		return formatRecordValue(value)
 	}
+}
 
-	// Apply minimum widths and padding
-	columns[0].Width = max(maxIDLen+2, 13)
-	columns[1].Width = max(maxNameLen+2, 36)
-	columns[2].Width = max(maxDNILen+2, 13)
+// historyValueColor colors grades and statuses of the grade history as elsewhere
+func historyValueColor(theme Theme, value any) Color {
+	switch value := value.(type) {
+	case float32:
+		return gradeValueColor(theme, value)
+	case string:
+		return statusColor(theme, value)
+	default:
+		return ColorDefault
+	}
+}
 
-	fmt.Println("Students found:")
-	printTableHeader(columns)
-	printTableSeparator(columns)
+func outputHistoryTable(w io.Writer, entries []HistoryEntry, opts OutputOptions) error {
+	if len(entries) == 0 && opts.Format != OutputHTML {
+		fmt.Fprintln(w, "No grade history yet.")
+		return nil
+	}
 
-	for _, student := range students {
-		printStudentTableRow(student, columns)
+	columns, err := selectColumns(historyColumns, entries, opts.Fields)
+	if err != nil {
+		return err
 	}
+	table := newFieldTable(w, opts, columns, entries)
 
-	printTableFooter(columns)
+	return renderTable(w, table, opts.Format, "Grade history")
 }
 
-func outputProfessorsJSON(professors []gosuv2.ProfessorBasicResponse) {
-	var professorData []ProfessorData
-	for _, professor := range professors {
-		data := ProfessorData{
-			Code:          professor.Code,
-			ProfessorName: professor.ProfessorName,
-			DNI:           professor.DNI,
-			WorkerID:      professor.WorkerID,
-		}
-		professorData = append(professorData, data)
+func outputSnapshotsTable(w io.Writer, snapshots []SnapshotInfo, opts OutputOptions) error {
+	if len(snapshots) == 0 && opts.Format != OutputHTML {
+		fmt.Fprintln(w, "No grade history yet.")
+		return nil
 	}
 
-	output, err := json.MarshalIndent(professorData, "", "  ")
+	columns, err := selectColumns(snapshotColumns, snapshots, opts.Fields)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error marshaling JSON: %v\n", err)
-		os.Exit(1)
+		return err
 	}
-	fmt.Println(string(output))
+	table := newFieldTable(w, opts, columns, snapshots)
+
+	return renderTable(w, table, opts.Format, "Snapshots")
 }
 
-func outputProfessorsTable(professors []gosuv2.ProfessorBasicResponse) {
-	if len(professors) == 0 {
-		fmt.Println("No professors found")
-		return
+func outputChangesTable(w io.Writer, changes []GradeChange, opts OutputOptions) error {
+	if len(changes) == 0 && opts.Format != OutputHTML {
+		fmt.Fprintln(w, "No changes.")
+		return nil
 	}
 
-	// Calculate column widths based on content
-	columns := []Column{
-		{"Code", 13, "left", true},
-		{"Professor Name", 36, "left", true},
-		{"DNI", 13, "left", true},
-		{"Worker ID", 13, "left", true},
+	columns, err := selectColumns(changeColumns, changes, opts.Fields)
+	if err != nil {
+		return err
 	}
+	table := newFieldTable(w, opts, columns, changes)
+
+	return renderTable(w, table, opts.Format, "Changes")
+}
 
-	// Adjust column widths based on actual content
-	maxCodeLen := len("Code")
-	maxNameLen := len("Professor Name")
-	maxDNILen := len("DNI")
-	maxWorkerLen := len("Worker ID")
+func outputSummaryTable(w io.Writer, summary Summary, opts OutputOptions) error {
+	table := newOutputTable(w, opts)
 
-	for _, professor := range professors {
-		if len(professor.Code) > maxCodeLen {
-			maxCodeLen = len(professor.Code)
-		}
-		if len(professor.ProfessorName) > maxNameLen {
-			maxNameLen = len(professor.ProfessorName)
-		}
-		if len(professor.DNI) > maxDNILen {
-			maxDNILen = len(professor.DNI)
-		}
-		if len(professor.WorkerID) > maxWorkerLen {
-			maxWorkerLen = len(professor.WorkerID)
-		}
+	table.Columns = []Column{{Name: "Summary"}, {Name: "Value", Wrap: true}}
+	for _, row := range summary.describe(opts.Theme) {
+		table.AddRow(row[0], row[1])
 	}
 
-	// Apply minimum widths and padding
-	columns[0].Width = max(maxCodeLen+2, 13)
-	columns[1].Width = max(maxNameLen+2, 36)
-	columns[2].Width = max(maxDNILen+2, 13)
-	columns[3].Width = max(maxWorkerLen+2, 13)
+	return renderTable(w, table, opts.Format, "Summary")
+}
 
-	fmt.Println("Professors found:")
-	printTableHeader(columns)
-	printTableSeparator(columns)
+func outputStudentsTable(w io.Writer, students []StudentData, opts OutputOptions) error {
+	if len(students) == 0 && opts.Format != OutputHTML {
+		fmt.Fprintln(w, "No students found")
+		return nil
+	}
 
-	for _, professor := range professors {
-		printProfessorTableRow(professor, columns)
+	columns, err := selectColumns(studentColumns, students, opts.Fields)
+	if err != nil {
+		return err
 	}
+	table := newFieldTable(w, opts, columns, students)
 
-	printTableFooter(columns)
+	if opts.Format == OutputTable {
+		fmt.Fprintln(w, "Students found:")
+	}
+	return renderTable(w, table, opts.Format, "Students")
 }
 
-// Text output functions (existing behavior)
-func outputGradesText(grades []gosuv2.SuvCurrentCourseGrades) {
-	for _, grade := range grades {
-		prettyPrintGradeCourse(grade)
-		fmt.Println()
+func outputProfessorsTable(w io.Writer, professors []ProfessorData, opts OutputOptions) error {
+	if len(professors) == 0 && opts.Format != OutputHTML {
+		fmt.Fprintln(w, "No professors found")
+		return nil
+	}
+
+	columns, err := selectColumns(professorColumns, professors, opts.Fields)
+	if err != nil {
+		return err
+	}
+	table := newFieldTable(w, opts, columns, professors)
+
+	if opts.Format == OutputTable {
+		fmt.Fprintln(w, "Professors found:")
 	}
+	return renderTable(w, table, opts.Format, "Professors")
 }
 
-func outputStudentsText(students []gosuv2.StudentBasicResponse) {
-	if len(students) == 0 {
-		fmt.Println("No students found")
-	} else {
-		fmt.Println("Students found:")
-		for _, student := range students {
-			fmt.Println()
-			fmt.Println("Code:", student.StudentID)
-			fmt.Println("Name:", student.StudentName)
-			fmt.Println("DNI:", student.DNI)
-		}
+// outputRecordsTable prints the results selected with --jsonpath as a plain table
+func outputRecordsTable(w io.Writer, r records, opts OutputOptions) error {
+	table := newOutputTable(w, opts)
+
+	for _, field := range r.fields {
+		table.Columns = append(table.Columns, Column{Name: field, Format: formatRecordValue})
 	}
+	for _, row := range r.rows {
+		table.AddRow(row...)
+	}
+
+	return renderTable(w, table, opts.Format, "Results")
 }
 
-func outputProfessorsText(professors []gosuv2.ProfessorBasicResponse) {
-	if len(professors) == 0 {
-		fmt.Println("No professors found")
-	} else {
-		fmt.Println("Professors found:")
-		for _, professor := range professors {
-			fmt.Println()
-			fmt.Println("Code:", professor.Code)
-			fmt.Println("Name:", professor.ProfessorName)
-			fmt.Println("DNI:", professor.DNI)
-			fmt.Println("Worker ID:", professor.WorkerID)
-		}
+// isTableFormat reports whether format prints results as a table
+func isTableFormat(format OutputFormat) bool {
+	return format == OutputTable || format == OutputMarkdown || format == OutputHTML
+}
+
+// newOutputTable returns an empty table for the table, markdown or html formats
+func newOutputTable(w io.Writer, opts OutputOptions) *Table {
+	if opts.Format != OutputTable {
+		return &Table{Border: BorderMarkdown, Theme: opts.Theme}
 	}
+	return NewTable(w, opts)
 }
 
-// Helper functions
-func getGradeColor(grade float32) string {
-	if grade < 13.5 {
-		return "\033[31m" // Red
+// renderTable writes table in the given table format
+func renderTable(w io.Writer, table *Table, format OutputFormat, title string) error {
+	if format == OutputHTML {
+		return table.RenderHTML(w, title)
 	}
-	return "\033[94m" // Light blue
+	return table.Render(w)
 }
 
-func getStatusColor(status string) string {
-	switch strings.ToUpper(status) {
-	case "PASSED":
-		return "\033[32m" // Green
-	case "FAILED":
-		return "\033[31m" // Red
-	case "PENDING":
-		return "\033[33m" // Yellow
-	default:
-		return "\033[0m" // Default
+// Text output functions (existing behavior)
+func outputGradesText(w io.Writer, grades []GradeData, colors Colorizer) {
+	for _, grade := range grades {
+		prettyPrintGradeCourse(w, grade, colors)
+		fmt.Fprintln(w)
 	}
 }
 
-func determineFinalStatus(grade gosuv2.SuvCurrentCourseGrades) string {
-	if grade.FinalStatus == gosuv2.PassedStatus {
-		return "PASSED"
-	} else {
-		if grade.Average1 != 0 && grade.Average2 != 0 && grade.Average3 != 0 {
-			if grade.Average >= 14 || grade.FinalAverage >= 14 {
-				return "PASSED"
-			} else {
-				return "FAILED"
-			}
-		} else {
-			return "PENDING"
+func outputProjectionsText(w io.Writer, projections []Projection, colors Colorizer) {
+	for _, projection := range projections {
+		fmt.Fprintln(w, "Course ID:", projection.CourseID)
+		fmt.Fprintln(w, "Course:", projection.CourseName)
+		printAverage(w, colors, projection.Average, "Average so far:")
+		if len(projection.RemainingUnits) > 0 {
+			fmt.Fprintln(w, "Remaining:", describeUnits(projection.RemainingUnits))
+		}
+		if projection.Needed != 0 {
+			fmt.Fprintf(w, "Needed: %s\n", colors.Paint(neededColor(colors.theme, projection.Needed), fmt.Sprintf("%.2f", projection.Needed)))
 		}
+		if projection.SubstituteNeeded != 0 {
+			fmt.Fprintf(w, "Substitute exam: %s, replacing unit %d\n", colors.Paint(neededColor(colors.theme, projection.SubstituteNeeded), fmt.Sprintf("%.2f", projection.SubstituteNeeded)), projection.SubstituteUnit)
+		}
+		fmt.Fprintln(w, "Plan:", projection.Plan)
+		fmt.Fprintln(w)
 	}
 }
 
-// Raw JSON output functions (no indentation for piping)
-func outputGradesRaw(grades []gosuv2.SuvCurrentCourseGrades) {
-	var gradeData []GradeData
-	for _, grade := range grades {
-		finalStatus := determineFinalStatus(grade)
-		data := GradeData{
-			CourseID:     grade.CourseID,
-			CourseName:   grade.CourseName,
-			Attempt:      grade.Attempt,
-			Average1:     grade.Average1,
-			Average2:     grade.Average2,
-			Average3:     grade.Average3,
-			Average4:     grade.Average4,
-			Average5:     grade.Average5,
-			Average6:     grade.Average6,
-			Substitute:   grade.Substitute,
-			Average:      grade.Average,
-			Postponed:    grade.Postponed,
-			FinalAverage: grade.FinalAverage,
-			Disabled:     grade.Disabled,
-			FinalStatus:  finalStatus,
-		}
-		gradeData = append(gradeData, data)
+func outputHistoryText(w io.Writer, entries []HistoryEntry, colors Colorizer) {
+	if len(entries) == 0 {
+		fmt.Fprintln(w, "No grade history yet.")
+		return
 	}
 
-	output, err := json.Marshal(gradeData)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error marshaling JSON: %v\n", err)
-		os.Exit(1)
+	for i, entry := range entries {
+		if i == 0 || entries[i-1].CourseID != entry.CourseID {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintf(w, "Course %d: %s\n", entry.CourseID, entry.CourseName)
+		}
+
+		to := colors.Paint(historyValueColor(colors.theme, entry.To), formatHistoryValue(entry.To))
+		if entry.From == nil {
+			fmt.Fprintf(w, "%s  %s: %s\n", formatHistoryTime(entry.Time), fieldLabel(entry.Field), to)
+		} else {
+			fmt.Fprintf(w, "%s  %s: %s -> %s\n", formatHistoryTime(entry.Time), fieldLabel(entry.Field), formatHistoryValue(entry.From), to)
+		}
 	}
-	fmt.Println(string(output))
 }
 
-func outputStudentsRaw(students []gosuv2.StudentBasicResponse) {
-	var studentData []StudentData
-	for _, student := range students {
-		data := StudentData{
-			StudentID:   student.StudentID,
-			StudentName: student.StudentName,
-			DNI:         student.DNI,
-		}
-		studentData = append(studentData, data)
+func outputSnapshotsText(w io.Writer, snapshots []SnapshotInfo) {
+	if len(snapshots) == 0 {
+		fmt.Fprintln(w, "No grade history yet.")
+		return
 	}
 
-	output, err := json.Marshal(studentData)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error marshaling JSON: %v\n", err)
-		os.Exit(1)
+	for _, snapshot := range snapshots {
+		fmt.Fprintf(w, "%d  %s  %s, %d courses\n", snapshot.ID, formatHistoryTime(snapshot.FetchedAt), snapshot.Semester, snapshot.Courses)
 	}
-	fmt.Println(string(output))
 }
 
-func outputProfessorsRaw(professors []gosuv2.ProfessorBasicResponse) {
-	var professorData []ProfessorData
-	for _, professor := range professors {
-		data := ProfessorData{
-			Code:          professor.Code,
-			ProfessorName: professor.ProfessorName,
-			DNI:           professor.DNI,
-			WorkerID:      professor.WorkerID,
-		}
-		professorData = append(professorData, data)
+func outputChangesText(w io.Writer, changes []GradeChange, colors Colorizer) {
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "No changes.")
+		return
 	}
 
-	output, err := json.Marshal(professorData)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error marshaling JSON: %v\n", err)
-		os.Exit(1)
+	for _, change := range changes {
+		fmt.Fprintln(w, change.describe(colors))
 	}
-	fmt.Println(string(output))
 }
 
-// Generic table helper functions
-func printTableHeader(columns []Column) {
-	// Top border
-	fmt.Print("╭")
-	for i, col := range columns {
-		fmt.Print(strings.Repeat("─", col.Width))
-		if i < len(columns)-1 {
-			fmt.Print("┬")
-		}
+func outputSummaryText(w io.Writer, summary Summary, colors Colorizer) {
+	for _, row := range summary.describe(colors.theme) {
+		fmt.Fprintf(w, "%s: %s\n", row[0].Text, colors.Paint(row[1].Color, row[1].Text))
 	}
-	fmt.Println("╮")
+}
 
-	// Header row
-	fmt.Print("│")
-	for _, col := range columns {
-		padding := col.Width - len(col.Name)
-		switch col.Align {
-		case "center":
-			leftPad := padding / 2
-			rightPad := padding - leftPad
-			fmt.Printf("%s%s%s│", strings.Repeat(" ", leftPad), col.Name, strings.Repeat(" ", rightPad))
-		case "right":
-			fmt.Printf("%s%s │", strings.Repeat(" ", padding-1), col.Name)
-		default: // left
-			fmt.Printf(" %-*s│", col.Width-1, col.Name)
+func outputStudentsText(w io.Writer, students []StudentData) {
+	if len(students) == 0 {
+		fmt.Fprintln(w, "No students found")
+	} else {
+		fmt.Fprintln(w, "Students found:")
+		for _, student := range students {
+			fmt.Fprintln(w)
+			fmt.Fprintln(w, "Code:", student.StudentID)
+			fmt.Fprintln(w, "Name:", student.StudentName)
+			fmt.Fprintln(w, "DNI:", student.DNI)
 		}
 	}
-	fmt.Println()
 }
 
-func printTableSeparator(columns []Column) {
-	fmt.Print("├")
-	for i, col := range columns {
-		fmt.Print(strings.Repeat("─", col.Width))
-		if i < len(columns)-1 {
-			fmt.Print("┼")
+func outputProfessorsText(w io.Writer, professors []ProfessorData) {
+	if len(professors) == 0 {
+		fmt.Fprintln(w, "No professors found")
+	} else {
+		fmt.Fprintln(w, "Professors found:")
+		for _, professor := range professors {
+			fmt.Fprintln(w)
+			fmt.Fprintln(w, "Code:", professor.Code)
+			fmt.Fprintln(w, "Name:", professor.ProfessorName)
+			fmt.Fprintln(w, "DNI:", professor.DNI)
+			fmt.Fprintln(w, "Worker ID:", professor.WorkerID)
 		}
 	}
-	fmt.Println("┤")
 }
 
-func printTableFooter(columns []Column) {
-	fmt.Print("╰")
-	for i, col := range columns {
-		fmt.Print(strings.Repeat("─", col.Width))
-		if i < len(columns)-1 {
-			fmt.Print("┴")
-		}
+// outputRaw prints data as compact JSON for piping
+func outputRaw(w io.Writer, data any) error {
+	output, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("error marshaling JSON: %w", err)
 	}
-	fmt.Println("╯")
+	fmt.Fprintln(w, string(output))
+	return nil
 }
 
-func printStudentTableRow(student gosuv2.StudentBasicResponse, columns []Column) {
-	fmt.Print("│")
+// outputYAML prints data as YAML, with the field names and order of its JSON encoding
+func outputYAML(w io.Writer, data any) error {
+	output, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("error marshaling JSON: %w", err)
+	}
 
-	for _, col := range columns {
-		var content string
-
-		switch col.Name {
-		case "Student ID":
-			content = student.StudentID
-		case "Student Name":
-			content = student.StudentName
-		case "DNI":
-			content = student.DNI
-		}
+	// JSON is YAML, decoding it into a node keeps the order of the fields
+	var node yaml.Node
+	if err := yaml.Unmarshal(output, &node); err != nil {
+		return fmt.Errorf("error converting JSON to YAML: %w", err)
+	}
+	setBlockStyle(&node)
 
-		// Apply formatting based on column alignment
-		padding := col.Width - len(content)
-		switch col.Align {
-		case "center":
-			leftPad := padding / 2
-			rightPad := padding - leftPad
-			fmt.Printf("%s%s%s│", strings.Repeat(" ", leftPad), content, strings.Repeat(" ", rightPad))
-		case "right":
-			fmt.Printf("%s%s │", strings.Repeat(" ", padding-1), content)
-		default: // left
-			fmt.Printf(" %-*s│", col.Width-1, content)
-		}
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return fmt.Errorf("error marshaling YAML: %w", err)
 	}
-	fmt.Println()
+	return encoder.Close()
 }
 
-func printProfessorTableRow(professor gosuv2.ProfessorBasicResponse, columns []Column) {
-	fmt.Print("│")
+// setBlockStyle drops the flow style and quotes nodes decoded from JSON come with
+func setBlockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		setBlockStyle(child)
+	}
+}
 
-	for _, col := range columns {
-		var content string
-
-		switch col.Name {
-		case "Code":
-			content = professor.Code
-		case "Professor Name":
-			content = professor.ProfessorName
-		case "DNI":
-			content = professor.DNI
-		case "Worker ID":
-			content = professor.WorkerID
-		}
+// outputNDJSON prints data as newline delimited JSON, one line per item if it is a list
+func outputNDJSON(w io.Writer, data any) error {
+	output, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("error marshaling JSON: %w", err)
+	}
 
-		// Apply formatting based on column alignment
-		padding := col.Width - len(content)
-		switch col.Align {
-		case "center":
-			leftPad := padding / 2
-			rightPad := padding - leftPad
-			fmt.Printf("%s%s%s│", strings.Repeat(" ", leftPad), content, strings.Repeat(" ", rightPad))
-		case "right":
-			fmt.Printf("%s%s │", strings.Repeat(" ", padding-1), content)
-		default: // left
-			fmt.Printf(" %-*s│", col.Width-1, content)
-		}
+	var items []json.RawMessage
+	if err := json.Unmarshal(output, &items); err != nil {
+		// Not a list, print it as a single record
+		fmt.Fprintln(w, string(output))
+		return nil
+	}
+
+	for _, item := range items {
+		fmt.Fprintln(w, string(item))
 	}
-	fmt.Println()
+	return nil
 }
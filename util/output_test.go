@@ -0,0 +1,290 @@
+package util_test
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/patitolabs/suvctl/util"
+	"github.com/patitolabs/suvctl/util/suvtest"
+	"github.com/spf13/viper"
+)
+
+var update = flag.Bool("update", false, "regenerate the golden files of the output tests")
+
+func TestMain(m *testing.M) {
+	// The golden files show the times of the grade history in UTC
+	time.Local = time.UTC
+	os.Exit(m.Run())
+}
+
+// outputFormats lists every format the golden tests render
+var outputFormats = []util.OutputFormat{
+	util.OutputText,
+	util.OutputTable,
+	util.OutputJSON,
+	util.OutputRaw,
+	util.OutputCSV,
+	util.OutputTSV,
+	util.OutputYAML,
+	util.OutputNDJSON,
+	util.OutputMarkdown,
+	util.OutputHTML,
+}
+
+// goldenOptions prints format in the default theme, keeping the colors the golden files
+// hold, which are left out of buffers by default
+func goldenOptions(format util.OutputFormat) util.OutputOptions {
+	return util.OutputOptions{Format: format, Color: util.ColorAlways, Theme: util.ThemePresets["default"]}
+}
+
+// outputCase renders one data set through an Output* dispatcher
+type outputCase struct {
+	name   string
+	render func(w io.Writer, opts util.OutputOptions) error
+}
+
+func outputCases() []outputCase {
+	fixtures := suvtest.DefaultFixtures()
+
+	var grades []util.GradeData
+	for _, course := range fixtures.GradesResponse().Courses {
+		grades = append(grades, util.NewGradeData(course, util.DefaultStatusRules))
+	}
+
+	var projections []util.Projection
+	for _, course := range fixtures.GradesResponse().Courses {
+		projections = append(projections, util.DefaultStatusRules.Project(course, nil))
+	}
+
+	summary := util.DefaultStatusRules.Summarize(fixtures.Grades.Semester, fixtures.GradesResponse().Courses, util.Catalog{3401: 4, 3402: 3})
+
+	snapshots := historySnapshots()
+	history := util.Timeline(snapshots, util.GradeFilter{CourseIDs: []int{3402, 3403}}, util.DefaultStatusRules)
+	changes := util.CompareGrades(snapshots[0].Courses, snapshots[1].Courses[1:3], util.GradeFilter{CourseIDs: []int{3401, 3403}}, util.DefaultStatusRules)
+
+	var students []util.StudentData
+	for _, student := range fixtures.Students {
+		students = append(students, util.NewStudentData(student))
+	}
+
+	var professors []util.ProfessorData
+	for _, professor := range fixtures.Professors {
+		professors = append(professors, util.NewProfessorData(professor))
+	}
+
+	return []outputCase{
+		{"grades", func(w io.Writer, opts util.OutputOptions) error { return util.OutputGrades(w, grades, opts) }},
+		{"grades-empty", func(w io.Writer, opts util.OutputOptions) error {
+			return util.OutputGrades(w, []util.GradeData{}, opts)
+		}},
+		{"projections", func(w io.Writer, opts util.OutputOptions) error { return util.OutputProjections(w, projections, opts) }},
+		{"summary", func(w io.Writer, opts util.OutputOptions) error { return util.OutputSummary(w, summary, opts) }},
+		{"history", func(w io.Writer, opts util.OutputOptions) error { return util.OutputHistory(w, history, opts) }},
+		{"changes", func(w io.Writer, opts util.OutputOptions) error { return util.OutputChanges(w, changes, opts) }},
+		{"changes-empty", func(w io.Writer, opts util.OutputOptions) error {
+			return util.OutputChanges(w, []util.GradeChange{}, opts)
+		}},
+		{"students", func(w io.Writer, opts util.OutputOptions) error { return util.OutputStudents(w, students, opts) }},
+		{"students-empty", func(w io.Writer, opts util.OutputOptions) error {
+			return util.OutputStudents(w, []util.StudentData{}, opts)
+		}},
+		{"professors", func(w io.Writer, opts util.OutputOptions) error { return util.OutputProfessors(w, professors, opts) }},
+		{"professors-empty", func(w io.Writer, opts util.OutputOptions) error {
+			return util.OutputProfessors(w, []util.ProfessorData{}, opts)
+		}},
+	}
+}
+
+func TestOutputGolden(t *testing.T) {
+	for _, format := range outputFormats {
+		for _, tc := range outputCases() {
+			name := tc.name + "." + string(format)
+
+			t.Run(name, func(t *testing.T) {
+				var buf bytes.Buffer
+				if err := tc.render(&buf, goldenOptions(format)); err != nil {
+					t.Fatal(err)
+				}
+
+				assertGolden(t, name, buf.Bytes())
+			})
+		}
+	}
+}
+
+// assertGolden compares got with testdata/golden/name.golden, rewriting it with -update
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", "golden", name+".golden")
+
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test ./util -update to create it)", err)
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s (run go test ./util -update to accept it)\n--- got\n%s\n--- want\n%s", path, got, want)
+	}
+}
+
+func TestOutputCSVOptions(t *testing.T) {
+	students := []util.StudentData{{StudentID: "1023300121", StudentName: `PEÑA; "PEPE"`, DNI: "71234567"}}
+	opts := util.OutputOptions{Format: util.OutputCSV, Delimiter: ';', NoHeader: true}
+
+	var buf bytes.Buffer
+	if err := util.OutputStudents(&buf, students, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "1023300121;\"PEÑA; \"\"PEPE\"\"\";71234567\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestGetOutputOptions(t *testing.T) {
+	viper.Set("output", "template={{.CourseName}}")
+	viper.Set("fields", []string{"course_id,course_name", "final_status"})
+	t.Cleanup(func() {
+		viper.Set("output", "")
+		viper.Set("fields", nil)
+		viper.Set("delimiter", "")
+	})
+
+	opts, err := util.GetOutputOptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.Format != util.OutputTemplate || opts.Template != "{{.CourseName}}" || len(opts.Fields) != 3 || opts.Delimiter != ',' {
+		t.Errorf("unexpected options: %+v", opts)
+	}
+
+	viper.Set("delimiter", "::")
+	if _, err := util.GetOutputOptions(); util.KindOf(err) != util.KindUsage {
+		t.Errorf("expected a usage error for a multi-character delimiter, got %v", err)
+	}
+
+	viper.Set("delimiter", "")
+	for _, output := range []string{"xml", "jsno", "templates={{.}}"} {
+		viper.Set("output", output)
+		if _, err := util.GetOutputOptions(); util.KindOf(err) != util.KindUsage {
+			t.Errorf("expected a usage error for output %s, got %v", output, err)
+		}
+	}
+}
+
+func TestOutputTemplate(t *testing.T) {
+	template := `{{range .}}{{.CourseID}} {{.CourseName | truncate 20 | padRight 20}} {{.Average1 | grade | padLeft 6}} {{.FinalAverage | number 1}} {{.FinalStatus | status}}
+{{end}}`
+	opts := goldenOptions(util.OutputTemplate)
+	opts.Template = template
+
+	var buf bytes.Buffer
+	if err := outputCases()[0].render(&buf, opts); err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "grades.template", buf.Bytes())
+
+	opts.Template = "{{.Missing}}"
+	if err := util.OutputGrades(io.Discard, []util.GradeData{{}}, opts); err == nil {
+		t.Error("expected an error for a template using an unknown field")
+	}
+}
+
+func TestOutputSelection(t *testing.T) {
+	cases := []struct {
+		name     string
+		fields   []string
+		jsonPath string
+		format   util.OutputFormat
+	}{
+		{"fields", []string{"course_id", "course_name", "final_average"}, "", util.OutputJSON},
+		{"fields", []string{"course_name", "final_status"}, "", util.OutputCSV},
+		{"fields", []string{"final_status", "course_name", "average_6", "disabled"}, "", util.OutputTable},
+		{"jsonpath", nil, "{.[*].course_name}", util.OutputRaw},
+		{"jsonpath", nil, "{.[-1]['course_id','final_status']}", util.OutputJSON},
+		{"jsonpath", nil, "{.[1:3]['course_id','course_name']}", util.OutputTable},
+	}
+
+	grades := outputCases()[0]
+
+	for _, tc := range cases {
+		name := "grades-" + tc.name + "." + string(tc.format)
+
+		t.Run(name, func(t *testing.T) {
+			opts := goldenOptions(tc.format)
+			opts.Fields, opts.JSONPath = tc.fields, tc.jsonPath
+
+			var buf bytes.Buffer
+			if err := grades.render(&buf, opts); err != nil {
+				t.Fatal(err)
+			}
+
+			assertGolden(t, name, buf.Bytes())
+		})
+	}
+
+	for _, bad := range []util.OutputOptions{
+		{Format: util.OutputJSON, Fields: []string{"course_id", "nope"}},
+		{Format: util.OutputJSON, JSONPath: "{.course_name}"},
+		{Format: util.OutputJSON, JSONPath: "{.[9]}"},
+		{Format: util.OutputJSON, JSONPath: "{.[*].unit.name}"},
+	} {
+		if err := grades.render(io.Discard, bad); util.KindOf(err) != util.KindUsage {
+			t.Errorf("expected a usage error for fields %v jsonpath %s, got %v", bad.Fields, bad.JSONPath, err)
+		}
+	}
+
+	// An end of 0 is an empty slice, not the end of the list
+	var buf bytes.Buffer
+	if err := grades.render(&buf, util.OutputOptions{Format: util.OutputRaw, JSONPath: "{.[0:0]}"}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "[]\n" {
+		t.Errorf("jsonpath {.[0:0]} printed %q, want []", buf.String())
+	}
+}
+
+func TestOutputColorMode(t *testing.T) {
+	grades := outputCases()[0]
+
+	cases := []struct {
+		mode    util.ColorMode
+		noColor string
+		colored bool
+	}{
+		{util.ColorAlways, "1", true},
+		{util.ColorNever, "", false},
+		{util.ColorAuto, "", false}, // a buffer is not a terminal
+	}
+
+	for _, format := range []util.OutputFormat{util.OutputText, util.OutputTable} {
+		for _, tc := range cases {
+			opts := goldenOptions(format)
+			opts.Color = tc.mode
+			t.Setenv("NO_COLOR", tc.noColor)
+
+			var buf bytes.Buffer
+			if err := grades.render(&buf, opts); err != nil {
+				t.Fatal(err)
+			}
+
+			if colored := bytes.Contains(buf.Bytes(), []byte("\033[")); colored != tc.colored {
+				t.Errorf("%s output with --color=%s NO_COLOR=%q: colored %v, want %v", format, tc.mode, tc.noColor, colored, tc.colored)
+			}
+		}
+	}
+}
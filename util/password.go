@@ -0,0 +1,60 @@
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ReadPasswordStdin reads the password from r, as given to --password-stdin
+func ReadPasswordStdin(r io.Reader) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("error reading password from stdin: %w", err)
+	}
+
+	password := strings.TrimRight(string(data), "\r\n")
+	if password == "" {
+		return "", errors.New("the password read from stdin is empty")
+	}
+
+	return password, nil
+}
+
+// RunPasswordCommand runs command through the shell and returns the first line of its
+// output, as password managers such as pass print the password there
+func RunPasswordCommand(command string) (string, error) {
+	cmd := shellCommand(context.Background(), command)
+
+	var stdout bytes.Buffer
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("error running password_command: %w", err)
+	}
+
+	line, _ := bufio.NewReader(&stdout).ReadString('\n')
+	password := strings.TrimRight(line, "\r\n")
+	if password == "" {
+		return "", errors.New("password_command did not print a password")
+	}
+
+	return password, nil
+}
+
+// shellCommand returns command run through the shell, killed when ctx is done
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
@@ -0,0 +1,41 @@
+package util_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/patitolabs/suvctl/util"
+)
+
+func TestReadPasswordStdin(t *testing.T) {
+	for input, want := range map[string]string{
+		"correct-horse":     "correct-horse",
+		"correct-horse\n":   "correct-horse",
+		"correct-horse\r\n": "correct-horse",
+		" spaced out \n":    " spaced out ",
+	} {
+		got, err := util.ReadPasswordStdin(strings.NewReader(input))
+		if err != nil || got != want {
+			t.Errorf("%q: got %q, %v, want %q", input, got, err, want)
+		}
+	}
+
+	for _, input := range []string{"", "\n"} {
+		if _, err := util.ReadPasswordStdin(strings.NewReader(input)); err == nil {
+			t.Errorf("%q: expected an error for an empty password", input)
+		}
+	}
+}
+
+func TestRunPasswordCommand(t *testing.T) {
+	got, err := util.RunPasswordCommand(`printf 'correct-horse\nurl: suv.example.com\n'`)
+	if err != nil || got != "correct-horse" {
+		t.Errorf("got %q, %v, want the first line", got, err)
+	}
+
+	for _, command := range []string{"exit 1", "true", "echo"} {
+		if _, err := util.RunPasswordCommand(command); err == nil {
+			t.Errorf("%s: expected an error", command)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// DefaultProfile is the profile made of the top-level settings of config.yml
+const DefaultProfile = "default"
+
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Profile represents a set of settings for one SUV account or host
+type Profile struct {
+	Name     string `json:"name" mapstructure:"-"`
+	Host     string `json:"host,omitempty" mapstructure:"host"`
+	Path     string `json:"path,omitempty" mapstructure:"path"`
+	UserCode string `json:"usercode,omitempty" mapstructure:"usercode"`
+	Output   string `json:"output,omitempty" mapstructure:"output"`
+}
+
+// ActiveProfileName returns the profile selected by --profile or the config file
+func ActiveProfileName() string {
+	if name := viper.GetString("profile"); name != "" {
+		return name
+	}
+	return DefaultProfile
+}
+
+// ActiveProfile returns the profile selected by --profile or the config file
+func ActiveProfile() (*Profile, error) {
+	return GetProfile(ActiveProfileName())
+}
+
+// GetProfile returns the profile with the given name
+func GetProfile(name string) (*Profile, error) {
+	if name == DefaultProfile {
+		// Read the file itself, as viper also holds flags and the active profile
+		settings, err := readConfigFile()
+		if err != nil {
+			return nil, err
+		}
+
+		setting := func(key string) string {
+			value, _ := lookupConfigValue(settings, key)
+			text, _ := value.(string)
+			return text
+		}
+
+		return &Profile{
+			Name:     DefaultProfile,
+			Host:     setting("host"),
+			Path:     setting("path"),
+			UserCode: setting("usercode"),
+			Output:   setting("output"),
+		}, nil
+	}
+
+	if !profileExists(name) {
+		return nil, Errorf(KindNotFound, "profile %q does not exist", name)
+	}
+
+	profile := &Profile{Name: name}
+	if err := viper.UnmarshalKey(profileConfigKey(name), profile); err != nil {
+		return nil, fmt.Errorf("error reading profile %q: %w", name, err)
+	}
+
+	return profile, nil
+}
+
+// ListProfiles returns the default profile followed by the named ones, sorted by name
+func ListProfiles() ([]Profile, error) {
+	names := make([]string, 0)
+	for name := range viper.GetStringMap("profiles") {
+		if name != DefaultProfile {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	profiles := make([]Profile, 0, len(names)+1)
+	for _, name := range append([]string{DefaultProfile}, names...) {
+		profile, err := GetProfile(name)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, *profile)
+	}
+
+	return profiles, nil
+}
+
+// AddProfile stores a new named profile in the config file
+func AddProfile(profile Profile) error {
+	if err := ValidateProfileName(profile.Name); err != nil {
+		return err
+	}
+	if profile.Name == DefaultProfile || profileExists(profile.Name) {
+		return Errorf(KindUsage, "profile %q already exists", profile.Name)
+	}
+
+	return UpdateConfig(func(settings map[string]any) {
+		setConfigValue(settings, profileConfigKey(profile.Name), profile.settings())
+	})
+}
+
+// RemoveProfile deletes a named profile from the config file and its credentials from store
+func RemoveProfile(name string, store CredentialStore) error {
+	if name == DefaultProfile {
+		return Errorf(KindUsage, "the default profile cannot be removed")
+	}
+
+	profile, err := GetProfile(name)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range credentialKeys {
+		if err := store.Delete(profile.CredentialKey(key)); err != nil {
+			return err
+		}
+	}
+
+	return UpdateConfig(func(settings map[string]any) {
+		deleteConfigValue(settings, profileConfigKey(name))
+		if profiles, _ := settings["profiles"].(map[string]any); len(profiles) == 0 {
+			delete(settings, "profiles")
+		}
+		if current, _ := settings["profile"].(string); current == name {
+			delete(settings, "profile")
+		}
+	})
+}
+
+// UseProfile makes the given profile the active one in the config file
+func UseProfile(name string) error {
+	if _, err := GetProfile(name); err != nil {
+		return err
+	}
+
+	return UpdateConfig(func(settings map[string]any) {
+		if name == DefaultProfile {
+			delete(settings, "profile")
+		} else {
+			settings["profile"] = name
+		}
+	})
+}
+
+// ValidateProfileName checks that name can be used as a profile name
+func ValidateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return Errorf(KindUsage, "invalid profile name %q: use lowercase letters, digits, '-' and '_'", name)
+	}
+	return nil
+}
+
+// ApplyProfile layers the settings of profile over the top-level ones, leaving alone
+// those given explicitly through any of flags
+func ApplyProfile(profile *Profile, flags ...*pflag.FlagSet) {
+	for key, value := range profile.settings() {
+		if flagChanged(key, flags) {
+			continue
+		}
+		viper.Set(key, value)
+	}
+}
+
+// CredentialKey returns the credential store key for the given secret of the profile
+func (p *Profile) CredentialKey(secret string) string {
+	if p.Name == DefaultProfile {
+		return secret
+	}
+	return profileConfigKey(p.Name) + "." + secret
+}
+
+// settings returns the non-empty settings of the profile keyed as in config.yml
+func (p *Profile) settings() map[string]string {
+	settings := map[string]string{}
+	for key, value := range map[string]string{
+		"host":     p.Host,
+		"path":     p.Path,
+		"usercode": p.UserCode,
+		"output":   p.Output,
+	} {
+		if value != "" {
+			settings[key] = value
+		}
+	}
+	return settings
+}
+
+func profileExists(name string) bool {
+	_, ok := viper.GetStringMap("profiles")[name]
+	return ok
+}
+
+func profileConfigKey(name string) string {
+	return "profiles." + name
+}
+
+func flagChanged(name string, flagSets []*pflag.FlagSet) bool {
+	for _, flags := range flagSets {
+		if flag := flags.Lookup(name); flag != nil && flag.Changed {
+			return true
+		}
+	}
+	return false
+}
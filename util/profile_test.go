@@ -0,0 +1,97 @@
+package util_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/patitolabs/suvctl/util"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+func TestProfiles(t *testing.T) {
+	useConfig(t, "host: suv.example.com\n")
+
+	store, err := util.NewCredentialStore(util.CredentialPlaintext, util.CredentialOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	work := util.Profile{Name: "work", Host: "suv.work.example.com", UserCode: "1023300121"}
+	if err := util.AddProfile(work); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"work", util.DefaultProfile} {
+		if err := util.AddProfile(util.Profile{Name: name}); util.KindOf(err) != util.KindUsage {
+			t.Errorf("adding profile %s again: got %v, want a usage error", name, err)
+		}
+	}
+	if err := util.AddProfile(util.Profile{Name: "Bad Name"}); util.KindOf(err) != util.KindUsage {
+		t.Errorf("adding an invalid name: got %v, want a usage error", err)
+	}
+
+	if err := util.UseProfile("work"); err != nil {
+		t.Fatal(err)
+	}
+	if name := util.ActiveProfileName(); name != "work" {
+		t.Fatalf("active profile is %s, want work", name)
+	}
+
+	// Removing the active profile forgets its session and goes back to the default one
+	profile, err := util.ActiveProfile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set(profile.CredentialKey(util.SessionKey), "def456"); err != nil {
+		t.Fatal(err)
+	}
+	if err := util.RemoveProfile("work", store); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(profile.CredentialKey(util.SessionKey)); !errors.Is(err, util.ErrCredentialNotFound) {
+		t.Errorf("the session of the removed profile is still stored: %v", err)
+	}
+	if name := util.ActiveProfileName(); name != util.DefaultProfile {
+		t.Errorf("active profile is %s after removing it, want the default one", name)
+	}
+
+	if err := util.RemoveProfile(util.DefaultProfile, store); util.KindOf(err) != util.KindUsage {
+		t.Errorf("removing the default profile: got %v, want a usage error", err)
+	}
+	for _, missing := range []func() error{
+		func() error { return util.RemoveProfile("work", store) },
+		func() error { return util.UseProfile("work") },
+		func() error { _, err := util.GetProfile("work"); return err },
+	} {
+		if err := missing(); util.KindOf(err) != util.KindNotFound {
+			t.Errorf("missing profile: got %v, want a not found error", err)
+		}
+	}
+}
+
+func TestApplyProfile(t *testing.T) {
+	useConfig(t, "host: suv.example.com\noutput: table\n")
+
+	// The active profile set in the config file must exist to be applied
+	viper.Set("profile", "missing")
+	if _, err := util.ActiveProfile(); util.KindOf(err) != util.KindNotFound {
+		t.Fatalf("missing active profile: got %v, want a not found error", err)
+	}
+
+	flags := pflag.NewFlagSet("suvctl", pflag.ContinueOnError)
+	flags.String("output", "", "")
+	if err := flags.Parse([]string{"--output", "json"}); err != nil {
+		t.Fatal(err)
+	}
+	viper.BindPFlag("output", flags.Lookup("output"))
+
+	util.ApplyProfile(&util.Profile{Name: "work", Host: "suv.work.example.com", Output: "yaml"}, flags)
+
+	if host := viper.GetString("host"); host != "suv.work.example.com" {
+		t.Errorf("host is %s, want the one of the profile", host)
+	}
+	if output := viper.GetString("output"); output != "json" {
+		t.Errorf("output is %s, want the one given with --output", output)
+	}
+}
@@ -0,0 +1,10 @@
+package util
+
+import "errors"
+
+// ErrNoTerminal is returned by a Prompter when it cannot ask, such as when stdin is not
+// a terminal
+var ErrNoTerminal = errors.New("stdin is not a terminal")
+
+// Prompter asks the user for a value, introduced by label. Secret values are not echoed.
+type Prompter func(label string, secret bool) (string, error)
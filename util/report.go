@@ -0,0 +1,257 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// GradeReport holds what the printable grade report shows
+type GradeReport struct {
+	UserCode       string
+	StudentName    string
+	Semester       string
+	EnrollmentType string
+	PaymentStatus  string
+	Grades         []GradeData
+	GeneratedAt    time.Time
+}
+
+// reportColumn is a column of the course table of the report
+type reportColumn struct {
+	title string
+	width float64 // in millimeters
+	value func(grade GradeData) float32
+}
+
+var reportGradeColumns = []reportColumn{
+	{"Unit 1", 14, func(g GradeData) float32 { return g.Average1 }},
+	{"Unit 2", 14, func(g GradeData) float32 { return g.Average2 }},
+	{"Unit 3", 14, func(g GradeData) float32 { return g.Average3 }},
+	{"Unit 4", 14, func(g GradeData) float32 { return g.Average4 }},
+	{"Unit 5", 14, func(g GradeData) float32 { return g.Average5 }},
+	{"Unit 6", 14, func(g GradeData) float32 { return g.Average6 }},
+	{"Subst", 15, func(g GradeData) float32 { return g.Substitute }},
+	{"Postponed", 19, func(g GradeData) float32 { return g.Postponed }},
+	{"Average", 16, func(g GradeData) float32 { return g.Average }},
+	{"Final", 15, func(g GradeData) float32 { return g.FinalAverage }},
+}
+
+const (
+	reportCourseWidth  = 14.0
+	reportNameWidth    = 70.0
+	reportAttemptWidth = 15.0
+	reportStatusWidth  = 24.0
+	reportBottomMargin = 12.0
+	reportFooterHeight = 16.0
+	// Rows are reportRowHeight high, and shrink down to reportMinRowHeight to keep the
+	// courses on one page
+	reportRowHeight    = 8.0
+	reportMinRowHeight = 5.0
+)
+
+// GradeReport fetches the grades of the current period selected by filter, along with the
+// student they belong to, for a printable report
+func (c *Client) GradeReport(ctx context.Context, filter GradeFilter) (*GradeReport, error) {
+	suvGradesResponse, err := c.GradesResponse(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	grades := filterGrades(suvGradesResponse.Courses, filter, c.StatusRules)
+	if len(grades) == 0 && !filter.empty() {
+		return nil, ErrNoCoursesFound
+	}
+
+	report := &GradeReport{
+		Semester:       suvGradesResponse.Semester,
+		EnrollmentType: suvGradesResponse.EnrollmentType,
+		PaymentStatus:  suvGradesResponse.PaymentStatus,
+		Grades:         grades,
+		GeneratedAt:    time.Now(),
+	}
+
+	// The student is only a nicety, leave it out when it cannot be found
+	report.UserCode, _ = c.UserCode()
+	if report.UserCode != "" {
+		students, err := c.SearchStudents(ctx, StudentQuery{Code: report.UserCode})
+		if err == nil && len(students) > 0 {
+			report.StudentName = students[0].StudentName
+		}
+	}
+
+	return report, nil
+}
+
+// WritePDF renders the report as a one page A4 PDF, with grades and statuses in the colors
+// of theme
+func (r *GradeReport) WritePDF(w io.Writer, theme Theme) error {
+	pdf := fpdf.New("L", "mm", "A4", "")
+	// Sort the resources, as the same report should always give the same file
+	pdf.SetCatalogSort(true)
+	pdf.SetCreationDate(r.GeneratedAt)
+	pdf.SetModificationDate(r.GeneratedAt)
+	pdf.SetTitle("Grade report", true)
+	pdf.SetCreator("suvctl", true)
+	pdf.SetMargins(10, 12, 10)
+	// The layout is fitted to a single page below, breaking pages would only hide a bug
+	pdf.SetAutoPageBreak(false, reportBottomMargin)
+	pdf.AddPage()
+
+	// The core fonts use cp1252, which covers the accents and Ñ of Spanish names
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	r.writeHeader(pdf, tr)
+
+	// Share the room left between the header row of the table and a row per course
+	_, pageHeight := pdf.GetPageSize()
+	room := pageHeight - reportBottomMargin - reportFooterHeight - pdf.GetY()
+	rowHeight := min(reportRowHeight, room/float64(len(r.Grades)+1))
+	if rowHeight < reportMinRowHeight {
+		fits := int(room/reportMinRowHeight) - 1
+		return Errorf(KindUsage, "the report fits up to %d courses on one page and %d were selected, narrow them down with --courseid or --course", fits, len(r.Grades))
+	}
+
+	r.writeCourses(pdf, tr, theme, rowHeight)
+	r.writeFooter(pdf, tr)
+
+	return pdf.Output(w)
+}
+
+func (r *GradeReport) writeHeader(pdf *fpdf.Fpdf, tr func(string) string) {
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.CellFormat(0, 10, tr("Grade report"), "", 1, "L", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.SetTextColor(0x61, 0x61, 0x61)
+	pdf.CellFormat(0, 6, tr("National University of Trujillo - SUV2"), "", 1, "L", false, 0, "")
+	pdf.Ln(3)
+
+	fields := [][2]string{
+		{"Student", r.StudentName},
+		{"Code", r.UserCode},
+		{"Period", r.Semester},
+		{"Enrollment", r.EnrollmentType},
+		{"Payment status", r.PaymentStatus},
+	}
+	for _, field := range fields {
+		if field[1] == "" {
+			continue
+		}
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.SetTextColor(0x21, 0x21, 0x21)
+		pdf.CellFormat(32, 6, tr(field[0]+":"), "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.CellFormat(0, 6, tr(field[1]), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(4)
+}
+
+func (r *GradeReport) writeCourses(pdf *fpdf.Fpdf, tr func(string) string, theme Theme, rowHeight float64) {
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.SetFillColor(0xf5, 0xf5, 0xf5)
+	pdf.SetDrawColor(0xd0, 0xd0, 0xd0)
+	pdf.SetTextColor(0x21, 0x21, 0x21)
+
+	pdf.CellFormat(reportCourseWidth, rowHeight, "Course", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(reportNameWidth, rowHeight, "Course name", "1", 0, "L", true, 0, "")
+	pdf.CellFormat(reportAttemptWidth, rowHeight, "Attempt", "1", 0, "C", true, 0, "")
+	for _, col := range reportGradeColumns {
+		pdf.CellFormat(col.width, rowHeight, col.title, "1", 0, "C", true, 0, "")
+	}
+	pdf.CellFormat(reportStatusWidth, rowHeight, "Status", "1", 1, "C", true, 0, "")
+
+	if len(r.Grades) == 0 {
+		pdf.SetFont("Helvetica", "I", 9)
+		pdf.CellFormat(0, rowHeight, "No courses found.", "", 1, "L", false, 0, "")
+		return
+	}
+
+	for _, grade := range r.Grades {
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.SetTextColor(0x21, 0x21, 0x21)
+
+		name := tr(grade.CourseName)
+		if grade.Disabled {
+			name += tr(" (disqualified)")
+		}
+
+		pdf.CellFormat(reportCourseWidth, rowHeight, fmt.Sprint(grade.CourseID), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(reportNameWidth, rowHeight, fitPDFText(pdf, name, reportNameWidth-2), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(reportAttemptWidth, rowHeight, fmt.Sprint(grade.Attempt), "1", 0, "C", false, 0, "")
+
+		for _, col := range reportGradeColumns {
+			value := col.value(grade)
+			setPDFTextColor(pdf, gradeValueTheme(theme, value))
+			pdf.CellFormat(col.width, rowHeight, formatGradeValue(value), "1", 0, "C", false, 0, "")
+		}
+
+		writeStatusBadge(pdf, grade.FinalStatus, theme.StatusColor(grade.FinalStatus), rowHeight)
+	}
+}
+
+// writeStatusBadge draws the status of a course as a badge of the given color in the
+// status cell, rowHeight high
+func writeStatusBadge(pdf *fpdf.Fpdf, status string, color Color, rowHeight float64) {
+	x, y := pdf.GetXY()
+	pdf.SetTextColor(0x21, 0x21, 0x21)
+	pdf.CellFormat(reportStatusWidth, rowHeight, "", "1", 1, "C", false, 0, "")
+
+	rgb, ok := color.RGB()
+	if !ok {
+		rgb = [3]uint8{0x75, 0x75, 0x75}
+	}
+
+	pdf.SetFillColor(int(rgb[0]), int(rgb[1]), int(rgb[2]))
+	pdf.RoundedRect(x+2, y+rowHeight*3/16, reportStatusWidth-4, rowHeight*5/8, 1.5, "1234", "F")
+
+	pdf.SetFont("Helvetica", "B", 8)
+	pdf.SetTextColor(0xff, 0xff, 0xff)
+	pdf.SetXY(x+2, y+rowHeight*3/16)
+	pdf.CellFormat(reportStatusWidth-4, rowHeight*5/8, status, "", 0, "C", false, 0, "")
+	left, _, _, _ := pdf.GetMargins()
+	pdf.SetXY(left, y+rowHeight)
+}
+
+func (r *GradeReport) writeFooter(pdf *fpdf.Fpdf, tr func(string) string) {
+	counts := map[string]int{}
+	for _, grade := range r.Grades {
+		counts[strings.ToUpper(grade.FinalStatus)]++
+	}
+
+	pdf.Ln(4)
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.SetTextColor(0x21, 0x21, 0x21)
+	summary := fmt.Sprintf("%d courses: %d passed, %d failed, %d pending",
+		len(r.Grades), counts["PASSED"], counts["FAILED"], counts["PENDING"])
+	pdf.CellFormat(0, 6, tr(summary), "", 1, "L", false, 0, "")
+
+	pdf.SetFont("Helvetica", "I", 8)
+	pdf.SetTextColor(0x75, 0x75, 0x75)
+	generated := "Generated by suvctl on " + r.GeneratedAt.Format("2006-01-02 15:04 MST")
+	pdf.CellFormat(0, 6, tr(generated), "", 1, "L", false, 0, "")
+}
+
+func setPDFTextColor(pdf *fpdf.Fpdf, color Color) {
+	rgb, ok := color.RGB()
+	if !ok {
+		rgb = [3]uint8{0x21, 0x21, 0x21}
+	}
+	pdf.SetTextColor(int(rgb[0]), int(rgb[1]), int(rgb[2]))
+}
+
+// fitPDFText shortens text, already translated to cp1252, until it fits in width
+// millimeters with the current font
+func fitPDFText(pdf *fpdf.Fpdf, text string, width float64) string {
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+	for len(text) > 0 && pdf.GetStringWidth(text+"...") > width {
+		text = text[:len(text)-1]
+	}
+	return strings.TrimRight(text, " ") + "..."
+}
@@ -0,0 +1,93 @@
+package util_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/patitolabs/suvctl/util"
+)
+
+// pdfText returns the content streams of a PDF, inflated, where the text drawn on its
+// pages can be found
+func pdfText(t *testing.T, data []byte) string {
+	t.Helper()
+
+	var text bytes.Buffer
+	for _, match := range regexp.MustCompile(`(?s)stream\r?\n(.*?)\r?\nendstream`).FindAllSubmatch(data, -1) {
+		r, err := zlib.NewReader(bytes.NewReader(match[1]))
+		if err != nil {
+			continue // not compressed, such as fonts
+		}
+		content, _ := io.ReadAll(r)
+		text.Write(content)
+	}
+	return text.String()
+}
+
+func TestGradeReportPDF(t *testing.T) {
+	report := &util.GradeReport{
+		UserCode:    "1023300121",
+		StudentName: "PEÑA CASTILLO, JOSÉ ÁNGEL",
+		Semester:    "2026-I",
+		Grades: []util.GradeData{
+			{CourseID: 3401, CourseName: "CALCULO DIFERENCIAL", Average1: 15.5, FinalStatus: "PASSED"},
+			{CourseID: 3402, CourseName: "FISICA GENERAL", Average1: 8, FinalStatus: "FAILED"},
+		},
+		GeneratedAt: time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC),
+	}
+	theme := util.ThemePresets["default"]
+
+	var first, second bytes.Buffer
+	if err := report.WritePDF(&first, theme); err != nil {
+		t.Fatal(err)
+	}
+	if err := report.WritePDF(&second, theme); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.HasPrefix(first.Bytes(), []byte("%PDF-")) || !bytes.Contains(first.Bytes(), []byte("%%EOF")) {
+		t.Fatal("the report is not a PDF document")
+	}
+	if !bytes.Equal(first.Bytes(), second.Bytes()) {
+		t.Error("the same report rendered twice differs")
+	}
+
+	if !bytes.Contains(first.Bytes(), []byte("/Count 1")) {
+		t.Error("the report is not a single page")
+	}
+	text := pdfText(t, first.Bytes())
+	for _, want := range []string{"CALCULO DIFERENCIAL", "FISICA GENERAL", "PASSED", "FAILED"} {
+		if !bytes.Contains([]byte(text), []byte("("+want+")")) {
+			t.Errorf("the report does not show %s", want)
+		}
+	}
+}
+
+func TestGradeReportPDFOnePage(t *testing.T) {
+	report := &util.GradeReport{Semester: "2026-I", GeneratedAt: time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)}
+	for i := range 20 {
+		report.Grades = append(report.Grades, util.GradeData{CourseID: 3401 + i, CourseName: fmt.Sprint("COURSE ", i+1), FinalStatus: "PENDING"})
+	}
+
+	// The rows shrink to keep the courses on one page
+	var buf bytes.Buffer
+	if err := report.WritePDF(&buf, util.Theme{}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("/Count 1")) || !bytes.Contains([]byte(pdfText(t, buf.Bytes())), []byte("(COURSE 20)")) {
+		t.Error("the courses do not fit on one page")
+	}
+
+	// Until they no longer fit
+	for i := range 20 {
+		report.Grades = append(report.Grades, util.GradeData{CourseID: 3421 + i, CourseName: fmt.Sprint("COURSE ", i+21)})
+	}
+	if err := report.WritePDF(io.Discard, util.Theme{}); util.KindOf(err) != util.KindUsage {
+		t.Errorf("expected a usage error for a report that does not fit, got %v", err)
+	}
+}
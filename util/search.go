@@ -1,32 +1,72 @@
 package util
 
 import (
+	"context"
+	"errors"
+
 	"github.com/patitolabs/gosuv2"
-	"github.com/spf13/cobra"
 )
 
-func (c *Client) SearchStudent(code, name, lastname, dni string) {
-	var (
-		err      error
-		students *[]gosuv2.StudentBasicResponse
-	)
+// ErrNoSearchCriteria is returned when a StudentQuery has no criteria to search by
+var ErrNoSearchCriteria = NewError(KindUsage, errors.New("you must provide a code, name and lastname, or dni"))
+
+// StudentQuery holds the criteria to search students by: a code, a name and lastname, or
+// a DNI. When several are given the last one in that order is used.
+type StudentQuery struct {
+	Code     string
+	Name     string
+	Lastname string
+	DNI      string
+}
 
-	if code != "" {
-		students, err = c.SuvClient.SearchStudentByCode(code)
+// SearchStudents returns the students matching query
+func (c *Client) SearchStudents(ctx context.Context, query StudentQuery) ([]StudentData, error) {
+	if query.Code == "" && (query.Name == "" || query.Lastname == "") && query.DNI == "" {
+		return nil, ErrNoSearchCriteria
 	}
-	if name != "" && lastname != "" {
-		students, err = c.SuvClient.SearchStudentByName(name, lastname)
+
+	var students []gosuv2.StudentBasicResponse
+
+	err := c.withSession(ctx, func() (err error) {
+		if query.Code != "" {
+			students, err = c.Backend.SearchStudentByCode(ctx, query.Code)
+		}
+		if query.Name != "" && query.Lastname != "" {
+			students, err = c.Backend.SearchStudentByName(ctx, query.Name, query.Lastname)
+		}
+		if query.DNI != "" {
+			students, err = c.Backend.SearchStudentByDni(ctx, query.DNI)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
-	if dni != "" {
-		students, err = c.SuvClient.SearchStudentByDni(dni)
+
+	studentData := make([]StudentData, 0, len(students))
+	for _, student := range students {
+		studentData = append(studentData, NewStudentData(student))
 	}
 
-	cobra.CheckErr(err)
-	OutputStudents(*students)
+	return studentData, nil
 }
 
-func (c *Client) SearchProfessor(name, lastname string) {
-	professors, err := c.SuvClient.SearchProfessor(name, lastname)
-	cobra.CheckErr(err)
-	OutputProfessors(*professors)
+// SearchProfessors returns the professors matching name and lastname
+func (c *Client) SearchProfessors(ctx context.Context, name, lastname string) ([]ProfessorData, error) {
+	var professors []gosuv2.ProfessorBasicResponse
+
+	err := c.withSession(ctx, func() (err error) {
+		professors, err = c.Backend.SearchProfessor(ctx, name, lastname)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	professorData := make([]ProfessorData, 0, len(professors))
+	for _, professor := range professors {
+		professorData = append(professorData, NewProfessorData(professor))
+	}
+
+	return professorData, nil
 }
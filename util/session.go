@@ -1,36 +1,210 @@
 package util
 
 import (
+	"context"
+	"errors"
 	"fmt"
-
-	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
+	"os"
 )
 
-func (c *Client) Login(usercode, password string) {
-	session, err := c.SuvClient.Login(usercode, password)
-	cobra.CheckErr(err)
+// ErrSessionExpired is returned when SUV does not accept the session
+var ErrSessionExpired = NewError(KindAuth, errors.New("the session has expired or is invalid, log in again"))
+
+// ErrNoCredentials is returned when there is no way to obtain a user code and password
+var ErrNoCredentials = NewError(KindAuth, errors.New("no credentials available to log in"))
+
+// ErrPlaintextRemember is returned when asked to remember the credentials in the plaintext
+// store, which would leave the password readable in the config file
+var ErrPlaintextRemember = NewError(KindUsage, errors.New("refusing to remember the password in the plaintext credential store, use the keyring or file store"))
+
+// Login creates a session for usercode, stores it and returns it. When SUV rejects the
+// remembered password, it is removed from the credential store, so that it is not tried
+// again before the prompt.
+func (c *Client) Login(ctx context.Context, usercode, password string) (string, error) {
+	session, err := c.Backend.Login(ctx, usercode, password)
+	if KindOf(err) == KindAuth {
+		return "", c.forgetPassword(password, err)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return session, c.SetPhpSession(session)
+}
+
+// RememberCredentials keeps the user code and password in the credential store, so that
+// the session can be renewed without asking for them when it expires. The plaintext store
+// is refused with ErrPlaintextRemember.
+func (c *Client) RememberCredentials(usercode, password string) error {
+	if err := CanRememberCredentials(c.Credentials); err != nil {
+		return err
+	}
+	if err := c.Credentials.Set(c.Profile.CredentialKey(UserCodeKey), usercode); err != nil {
+		return err
+	}
+	return c.Credentials.Set(c.Profile.CredentialKey(PasswordKey), password)
+}
+
+// forgetPassword removes the remembered password when it is the one that failed to log in
+// with loginErr
+func (c *Client) forgetPassword(password string, loginErr error) error {
+	stored, err := c.storedCredential(PasswordKey)
+	if err != nil || stored == "" || stored != password {
+		return loginErr
+	}
+
+	if err := c.Credentials.Delete(c.Profile.CredentialKey(PasswordKey)); err != nil {
+		return fmt.Errorf("%w (forgetting the remembered password failed: %v)", loginErr, err)
+	}
+	return fmt.Errorf("%w (the remembered password was forgotten, log in again)", loginErr)
+}
+
+// CanRememberCredentials returns ErrPlaintextRemember when store is the plaintext store,
+// to check it before logging in
+func CanRememberCredentials(store CredentialStore) error {
+	if _, ok := store.(*plaintextStore); ok {
+		return ErrPlaintextRemember
+	}
+	return nil
+}
 
-	c.SetPhpSession(*session)
+// Logout destroys the session in SUV and forgets it along with any remembered
+// credentials. With force, they are forgotten even if SUV fails to log out.
+func (c *Client) Logout(ctx context.Context, force bool) error {
+	err := c.Backend.Logout(ctx)
+	if err != nil && !force {
+		return err
+	}
 
-	if viper.GetBool("detailed") {
-		fmt.Println("Login successful. Session ID:", *session)
-	} else {
-		fmt.Println("Login successful")
+	if forgetErr := c.forgetCredentials(); forgetErr != nil {
+		return forgetErr
 	}
+
+	return err
 }
 
-func (c *Client) Logout() {
-	err := c.SuvClient.Logout()
+// forgetCredentials removes the session and any remembered credentials, so that a later
+// command does not log in again on its own
+func (c *Client) forgetCredentials() error {
+	if err := c.SetPhpSession(""); err != nil {
+		return err
+	}
+	if err := c.Credentials.Delete(c.Profile.CredentialKey(UserCodeKey)); err != nil {
+		return err
+	}
+	return c.Credentials.Delete(c.Profile.CredentialKey(PasswordKey))
+}
+
+// SessionStatus reports whether SUV still accepts the session of the client
+func (c *Client) SessionStatus(ctx context.Context) (bool, error) {
+	if c.session == "" {
+		return false, nil
+	}
 
-	if viper.GetBool("force") {
-		viper.Set("session", "")
-		viper.Set("detailed", false)
-		viper.WriteConfig()
+	_, err := c.Backend.GetSuvGradesResponse(ctx)
+	if errors.Is(err, ErrSessionExpired) {
+		return false, nil
 	}
 
-	cobra.CheckErr(err)
+	return err == nil, err
+}
+
+// withSession runs request and, when SUV rejects the session, logs in again and retries
+// it once. The original error is kept when no credentials are available.
+func (c *Client) withSession(ctx context.Context, request func() error) error {
+	err := request()
+	if !errors.Is(err, ErrSessionExpired) {
+		return err
+	}
+
+	if loginErr := c.relogin(ctx); loginErr != nil {
+		if errors.Is(loginErr, ErrNoCredentials) {
+			return err
+		}
+		return fmt.Errorf("%w (logging in again failed: %v)", err, loginErr)
+	}
+
+	return request()
+}
+
+// relogin creates a new session with the credentials available and stores it
+func (c *Client) relogin(ctx context.Context) error {
+	usercode, password, err := c.LoginCredentials("", "")
+	if err != nil {
+		return err
+	}
+
+	session, err := c.Login(ctx, usercode, password)
+	if err != nil {
+		return err
+	}
+
+	if c.OnRelogin != nil {
+		c.OnRelogin(session)
+	}
+
+	return nil
+}
+
+// UserCode returns the user code from SUVCTL_USERCODE, the profile or the credential
+// store, in that order, or "" if none of them has it
+func (c *Client) UserCode() (string, error) {
+	if usercode := os.Getenv("SUVCTL_USERCODE"); usercode != "" {
+		return usercode, nil
+	}
+	if c.Profile.UserCode != "" {
+		return c.Profile.UserCode, nil
+	}
+	return c.storedCredential(UserCodeKey)
+}
+
+// LoginCredentials completes the given user code and password, taking the missing ones
+// from the SUVCTL_USERCODE and SUVCTL_PASSWORD environment variables, the profile, the
+// password command, the credential store or the prompter, in that order
+func (c *Client) LoginCredentials(usercode, password string) (string, string, error) {
+	if usercode == "" {
+		known, err := c.UserCode()
+		if err != nil {
+			return "", "", err
+		}
+		usercode = known
+	}
+
+	if password == "" {
+		password = os.Getenv("SUVCTL_PASSWORD")
+	}
+	if password == "" && c.PasswordCommand != "" {
+		command, err := RunPasswordCommand(c.PasswordCommand)
+		if err != nil {
+			return "", "", err
+		}
+		password = command
+	}
+	if password == "" {
+		stored, err := c.storedCredential(PasswordKey)
+		if err != nil {
+			return "", "", err
+		}
+		password = stored
+	}
+
+	var err error
+	if usercode == "" && c.Prompt != nil {
+		usercode, err = c.Prompt("User code: ", false)
+	}
+	if err == nil && password == "" && c.Prompt != nil {
+		password, err = c.Prompt(fmt.Sprintf("Password for %s: ", usercode), true)
+	}
+	if errors.Is(err, ErrNoTerminal) {
+		return "", "", ErrNoCredentials
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	if usercode == "" || password == "" {
+		return "", "", ErrNoCredentials
+	}
 
-	c.SetPhpSession("")
-	fmt.Println("Logout successful")
+	return usercode, password, nil
 }
@@ -0,0 +1,146 @@
+package util_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/patitolabs/suvctl/util"
+	"github.com/patitolabs/suvctl/util/suvtest"
+)
+
+func TestLoginForgetsRejectedPassword(t *testing.T) {
+	t.Setenv("SUVCTL_USERCODE", "")
+	t.Setenv("SUVCTL_PASSWORD", "")
+
+	profile := &util.Profile{Name: "default"}
+	store := memoryStore{}
+	c := util.NewClient(suvtest.NewBackend(suvtest.DefaultFixtures()), store, profile)
+
+	if err := c.RememberCredentials("1023300121", "stale-password"); err != nil {
+		t.Fatal(err)
+	}
+
+	usercode, password, err := c.LoginCredentials("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Login(context.Background(), usercode, password); util.KindOf(err) != util.KindAuth {
+		t.Fatalf("expected an auth error for the remembered password, got %v", err)
+	}
+
+	if _, ok := store[profile.CredentialKey(util.PasswordKey)]; ok {
+		t.Error("the rejected password is still remembered")
+	}
+	if _, ok := store[profile.CredentialKey(util.UserCodeKey)]; !ok {
+		t.Error("the user code was forgotten along with the password")
+	}
+
+	// A password typed in that SUV rejects leaves the remembered one alone
+	if err := c.RememberCredentials("1023300121", "correct-horse"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Login(context.Background(), "1023300121", "typo"); util.KindOf(err) != util.KindAuth {
+		t.Fatalf("expected an auth error for a wrong password, got %v", err)
+	}
+	if store[profile.CredentialKey(util.PasswordKey)] != "correct-horse" {
+		t.Error("a wrong password typed in dropped the remembered one")
+	}
+}
+
+func TestLoginCredentialsPrecedence(t *testing.T) {
+	type sources struct {
+		arg, env, profile, command, stored, prompt string
+	}
+
+	cases := []struct {
+		name     string
+		usercode sources
+		password sources
+		want     [2]string
+	}{
+		{
+			"arguments first",
+			sources{arg: "arg", env: "env", profile: "profile", stored: "stored", prompt: "prompt"},
+			sources{arg: "arg", env: "env", command: "command", stored: "stored", prompt: "prompt"},
+			[2]string{"arg", "arg"},
+		},
+		{
+			"environment",
+			sources{env: "env", profile: "profile", stored: "stored", prompt: "prompt"},
+			sources{env: "env", command: "command", stored: "stored", prompt: "prompt"},
+			[2]string{"env", "env"},
+		},
+		{
+			"profile and password command",
+			sources{profile: "profile", stored: "stored", prompt: "prompt"},
+			sources{command: "command", stored: "stored", prompt: "prompt"},
+			[2]string{"profile", "command"},
+		},
+		{
+			"credential store",
+			sources{stored: "stored", prompt: "prompt"},
+			sources{stored: "stored", prompt: "prompt"},
+			[2]string{"stored", "stored"},
+		},
+		{
+			"prompt last",
+			sources{prompt: "prompt"},
+			sources{prompt: "prompt"},
+			[2]string{"prompt", "prompt"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("SUVCTL_USERCODE", tc.usercode.env)
+			t.Setenv("SUVCTL_PASSWORD", tc.password.env)
+
+			profile := &util.Profile{Name: "default", UserCode: tc.usercode.profile}
+			store := memoryStore{}
+			if tc.usercode.stored != "" {
+				store[profile.CredentialKey(util.UserCodeKey)] = tc.usercode.stored
+			}
+			if tc.password.stored != "" {
+				store[profile.CredentialKey(util.PasswordKey)] = tc.password.stored
+			}
+
+			c := util.NewClient(suvtest.NewBackend(suvtest.DefaultFixtures()), store, profile)
+			if tc.password.command != "" {
+				c.PasswordCommand = "echo " + tc.password.command
+			}
+			c.Prompt = func(label string, secret bool) (string, error) {
+				if secret {
+					return tc.password.prompt, nil
+				}
+				return tc.usercode.prompt, nil
+			}
+
+			usercode, password, err := c.LoginCredentials(tc.usercode.arg, tc.password.arg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := [2]string{usercode, password}; got != tc.want {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	// Without a prompter, missing credentials are reported rather than asked for
+	t.Setenv("SUVCTL_USERCODE", "")
+	t.Setenv("SUVCTL_PASSWORD", "")
+	c := util.NewClient(suvtest.NewBackend(suvtest.DefaultFixtures()), memoryStore{}, &util.Profile{Name: "default"})
+	if _, _, err := c.LoginCredentials("1023300121", ""); !errors.Is(err, util.ErrNoCredentials) {
+		t.Errorf("no password: got %v, want ErrNoCredentials", err)
+	}
+
+	// A failing password command stops the login instead of falling back to the prompt
+	c.PasswordCommand = "exit 3"
+	c.Prompt = func(label string, secret bool) (string, error) {
+		t.Error("prompted after the password command failed")
+		return "", nil
+	}
+	if _, _, err := c.LoginCredentials("1023300121", ""); err == nil {
+		t.Error("expected an error for a failing password command")
+	}
+}
@@ -0,0 +1,227 @@
+package util
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/patitolabs/gosuv2"
+	"github.com/spf13/viper"
+)
+
+// The final statuses of a course
+const (
+	StatusPassed  = "PASSED"
+	StatusFailed  = "FAILED"
+	StatusPending = "PENDING"
+)
+
+// Rounding is how a grade is rounded before comparing it with the passing grade
+type Rounding string
+
+const (
+	// RoundHalfUp rounds to the nearest integer, halves up, so 13.5 counts as 14
+	RoundHalfUp Rounding = "half-up"
+	// RoundNone compares grades as they are
+	RoundNone Rounding = "none"
+)
+
+// defaultUnits is the number of units assumed for a course when SUV does not list them
+const defaultUnits = 3
+
+// StatusRules decide the final status of a course from its grades
+type StatusRules struct {
+	PassingGrade float32  `mapstructure:"passing_grade"`
+	Rounding     Rounding `mapstructure:"rounding"`
+	// Weights are the unit weights used for courses SUV gives none for
+	Weights []float32 `mapstructure:"weights"`
+}
+
+// DefaultStatusRules are the rules of the university: 14 passes, after rounding halves up
+var DefaultStatusRules = StatusRules{PassingGrade: 14, Rounding: RoundHalfUp}
+
+// CourseStatus is the final status of a course and why it was given
+type CourseStatus struct {
+	Status string
+	Reason string
+}
+
+// GetStatusRules returns the rules from the grading section of viper config, with the
+// defaults for the settings it leaves out
+func GetStatusRules() (StatusRules, error) {
+	rules := DefaultStatusRules
+	if err := viper.UnmarshalKey("grading", &rules); err != nil {
+		return StatusRules{}, Errorf(KindUsage, "invalid grading settings: %w", err)
+	}
+
+	switch rules.Rounding {
+	case RoundHalfUp, RoundNone:
+	default:
+		return StatusRules{}, Errorf(KindUsage, "unknown grading rounding %q (use %s or %s)", rules.Rounding, RoundHalfUp, RoundNone)
+	}
+	if rules.PassingGrade <= 0 || rules.PassingGrade > 20 {
+		return StatusRules{}, Errorf(KindUsage, "invalid grading passing_grade %v, it must be between 0 and 20", rules.PassingGrade)
+	}
+	if err := ValidateWeights(rules.Weights); err != nil {
+		return StatusRules{}, Errorf(KindUsage, "invalid grading weights: %w", err)
+	}
+
+	return rules, nil
+}
+
+// Round applies the rounding rule to a grade
+func (r StatusRules) Round(grade float32) float32 {
+	if r.Rounding == RoundNone {
+		return grade
+	}
+	// Go through the two decimals SUV shows first, so that 13.4999 read as a float32
+	// does not fall below 13.5
+	hundredths := math.Round(float64(grade) * 100)
+	return float32(math.Floor(hundredths/100 + 0.5))
+}
+
+// Passes reports whether a grade reaches the passing grade once rounded
+func (r StatusRules) Passes(grade float32) bool {
+	return r.Round(grade) >= r.PassingGrade
+}
+
+// MinimumGrade returns the lowest grade that passes once rounded
+func (r StatusRules) MinimumGrade() float32 {
+	if r.Rounding == RoundNone {
+		return r.PassingGrade
+	}
+	return r.PassingGrade - 0.5
+}
+
+// ValidateWeights checks unit weights, one for each of up to six units
+func ValidateWeights(weights []float32) error {
+	if len(weights) > len(unitAverages(gosuv2.SuvCurrentCourseGrades{})) {
+		return Errorf(KindUsage, "%d weights given, courses have up to 6 units", len(weights))
+	}
+	for i, weight := range weights {
+		if weight <= 0 {
+			return Errorf(KindUsage, "the weight of unit %d must be positive", i+1)
+		}
+	}
+	return nil
+}
+
+// Evaluate decides the final status of a course. A disqualified course fails, a course
+// with units that have no grade yet is pending unless a substitute or postponed exam
+// was taken, and otherwise its final grade is rounded and compared with the passing
+// grade. A course SUV marks as passed always passes.
+func (r StatusRules) Evaluate(course gosuv2.SuvCurrentCourseGrades) CourseStatus {
+	status := r.evaluate(course)
+	if course.FinalStatus == gosuv2.PassedStatus && status.Status != StatusPassed {
+		return CourseStatus{StatusPassed, "marked as passed in SUV"}
+	}
+	return status
+}
+
+func (r StatusRules) evaluate(course gosuv2.SuvCurrentCourseGrades) CourseStatus {
+	if course.Disabled {
+		return CourseStatus{StatusFailed, "disqualified in the course"}
+	}
+
+	missing := missingUnits(course, len(r.unitWeights(course, nil)))
+	if len(missing) > 0 && course.Substitute == 0 && course.Postponed == 0 {
+		return CourseStatus{StatusPending, describeUnits(missing) + " " + plural(missing, "has", "have") + " no grade yet"}
+	}
+
+	name, grade := finalGrade(course)
+	if grade == 0 {
+		return CourseStatus{StatusPending, "no final grade yet"}
+	}
+
+	described := fmt.Sprintf("%s %.2f", name, grade)
+	if rounded := r.Round(grade); rounded != grade {
+		described += fmt.Sprintf(" rounds to %g", rounded)
+	}
+	if r.Passes(grade) {
+		return CourseStatus{StatusPassed, fmt.Sprintf("%s, passing grade is %g", described, r.PassingGrade)}
+	}
+	return CourseStatus{StatusFailed, fmt.Sprintf("%s, below the passing grade of %g", described, r.PassingGrade)}
+}
+
+// unitAverages returns the averages of the six units a course can have
+func unitAverages(course gosuv2.SuvCurrentCourseGrades) []float32 {
+	return []float32{course.Average1, course.Average2, course.Average3, course.Average4, course.Average5, course.Average6}
+}
+
+// unitWeights returns the weights of the units of a course: the given ones, the ones from
+// SUV, the ones from the grading settings or, without any, equal weights for at least
+// three units and up to the last one with a grade, in that order
+func (r StatusRules) unitWeights(course gosuv2.SuvCurrentCourseGrades, weights []float32) []float32 {
+	averages := unitAverages(course)
+	for _, candidate := range [][]float32{weights, course.Weights, r.Weights} {
+		if len(candidate) > 0 {
+			return candidate[:min(len(candidate), len(averages))]
+		}
+	}
+
+	units := defaultUnits
+	for i, average := range averages {
+		if average != 0 {
+			units = max(units, i+1)
+		}
+	}
+
+	equal := make([]float32, units)
+	for i := range equal {
+		equal[i] = 1
+	}
+	return equal
+}
+
+// unitGraded reports whether unit i, counting from 0, of a course is graded, according to
+// the unit statuses from SUV or, without them, to its average
+func unitGraded(course gosuv2.SuvCurrentCourseGrades, i int) bool {
+	if i < len(course.Statuses) {
+		return course.Statuses[i] == 1
+	}
+	return unitAverages(course)[i] != 0
+}
+
+// missingUnits lists the numbers of the first units of a course that are not graded yet
+func missingUnits(course gosuv2.SuvCurrentCourseGrades, units int) []int {
+	var missing []int
+	for i := range units {
+		if !unitGraded(course, i) {
+			missing = append(missing, i+1)
+		}
+	}
+	return missing
+}
+
+// finalGrade returns the grade that decides the status of a course and what it is
+func finalGrade(course gosuv2.SuvCurrentCourseGrades) (string, float32) {
+	switch {
+	case course.FinalAverage != 0:
+		return "final average", course.FinalAverage
+	case course.Postponed != 0:
+		return "postponed exam", course.Postponed
+	default:
+		return "average", course.Average
+	}
+}
+
+// describeUnits names units as in "unit 2" or "units 2 and 3"
+func describeUnits(units []int) string {
+	words := make([]string, len(units))
+	for i, unit := range units {
+		words[i] = strconv.Itoa(unit)
+	}
+
+	if len(words) == 1 {
+		return "unit " + words[0]
+	}
+	return "units " + strings.Join(words[:len(words)-1], ", ") + " and " + words[len(words)-1]
+}
+
+func plural[T any](items []T, singular, plural string) string {
+	if len(items) == 1 {
+		return singular
+	}
+	return plural
+}
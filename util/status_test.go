@@ -0,0 +1,62 @@
+package util_test
+
+import (
+	"testing"
+
+	"github.com/patitolabs/gosuv2"
+	"github.com/patitolabs/suvctl/util"
+)
+
+func TestStatusRules(t *testing.T) {
+	noRounding := util.StatusRules{PassingGrade: 14, Rounding: util.RoundNone}
+
+	cases := []struct {
+		name   string
+		rules  util.StatusRules
+		course gosuv2.SuvCurrentCourseGrades
+		want   util.CourseStatus
+	}{
+		{
+			"13.5 rounds up",
+			util.DefaultStatusRules,
+			gosuv2.SuvCurrentCourseGrades{Average1: 13, Average2: 14, Average3: 13.5, Average: 13.5},
+			util.CourseStatus{Status: "PASSED", Reason: "average 13.50 rounds to 14, passing grade is 14"},
+		},
+		{
+			"13.5 without rounding",
+			noRounding,
+			gosuv2.SuvCurrentCourseGrades{Average1: 13, Average2: 14, Average3: 13.5, Average: 13.5},
+			util.CourseStatus{Status: "FAILED", Reason: "average 13.50, below the passing grade of 14"},
+		},
+		{
+			"fourth unit from the weights",
+			util.DefaultStatusRules,
+			gosuv2.SuvCurrentCourseGrades{Average1: 15, Average2: 15, Average3: 15, Average: 15, Weights: []float32{0.25, 0.25, 0.25, 0.25}},
+			util.CourseStatus{Status: "PENDING", Reason: "unit 4 has no grade yet"},
+		},
+		{
+			"postponed exam",
+			util.DefaultStatusRules,
+			gosuv2.SuvCurrentCourseGrades{Average1: 8, Average2: 9, Average: 8.5, Postponed: 14},
+			util.CourseStatus{Status: "PASSED", Reason: "postponed exam 14.00, passing grade is 14"},
+		},
+		{
+			"substitute exam replaces a missing unit",
+			util.DefaultStatusRules,
+			gosuv2.SuvCurrentCourseGrades{Average1: 12, Average3: 11, Substitute: 10, Average: 11, FinalAverage: 11},
+			util.CourseStatus{Status: "FAILED", Reason: "final average 11.00, below the passing grade of 14"},
+		},
+		{
+			"passed in SUV",
+			util.DefaultStatusRules,
+			gosuv2.SuvCurrentCourseGrades{Average1: 12, FinalStatus: gosuv2.PassedStatus},
+			util.CourseStatus{Status: "PASSED", Reason: "marked as passed in SUV"},
+		},
+	}
+
+	for _, tc := range cases {
+		if got := tc.rules.Evaluate(tc.course); got != tc.want {
+			t.Errorf("%s: got %+v, want %+v", tc.name, got, tc.want)
+		}
+	}
+}
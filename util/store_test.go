@@ -0,0 +1,23 @@
+package util_test
+
+import "github.com/patitolabs/suvctl/util"
+
+// memoryStore is a CredentialStore kept in memory
+type memoryStore map[string]string
+
+func (s memoryStore) Get(key string) (string, error) {
+	if value, ok := s[key]; ok {
+		return value, nil
+	}
+	return "", util.ErrCredentialNotFound
+}
+
+func (s memoryStore) Set(key, value string) error {
+	s[key] = value
+	return nil
+}
+
+func (s memoryStore) Delete(key string) error {
+	delete(s, key)
+	return nil
+}
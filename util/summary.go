@@ -0,0 +1,170 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/patitolabs/gosuv2"
+)
+
+// Summary is the overall picture of a period
+type Summary struct {
+	Semester     string `json:"semester"`
+	Courses      int    `json:"courses"`
+	Passed       int    `json:"passed"`
+	Failed       int    `json:"failed"`
+	Pending      int    `json:"pending"`
+	Disqualified int    `json:"disqualified"`
+	// Average is the average of the courses with a final grade, weighted by their credits
+	// when Weighted is set
+	Average  float32 `json:"average"`
+	Credits  float32 `json:"credits"`
+	Weighted bool    `json:"weighted"`
+	// MissingCredits lists the courses the catalog has no credits for
+	MissingCredits []int       `json:"missing_credits"`
+	AtRisk         CourseRisks `json:"at_risk"`
+}
+
+// CourseRisk is a course that has failed or may fail, and what it still needs
+type CourseRisk struct {
+	CourseID   int    `json:"course_id"`
+	CourseName string `json:"course_name"`
+	Plan       string `json:"plan"`
+}
+
+// CourseRisks are the courses at risk of a period
+type CourseRisks []CourseRisk
+
+// String lists the IDs of the courses, as in spreadsheets
+func (r CourseRisks) String() string {
+	ids := make([]string, len(r))
+	for i, risk := range r {
+		ids[i] = strconv.Itoa(risk.CourseID)
+	}
+	return strings.Join(ids, " ")
+}
+
+// Summary sums up the courses of the current period selected by filter, weighting their
+// grades with the credits from the catalog of the client
+func (c *Client) Summary(ctx context.Context, filter GradeFilter) (*Summary, error) {
+	suvGradesResponse, err := c.GradesResponse(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var courses []gosuv2.SuvCurrentCourseGrades
+	for _, course := range suvGradesResponse.Courses {
+		if filter.matches(course) {
+			courses = append(courses, course)
+		}
+	}
+	if len(courses) == 0 && !filter.empty() {
+		return nil, ErrNoCoursesFound
+	}
+
+	summary := c.StatusRules.Summarize(suvGradesResponse.Semester, courses, c.Catalog)
+	return &summary, nil
+}
+
+// Summarize counts the courses of a period by status and averages their final grades,
+// weighted by their credits in catalog. When the catalog has the credits of none of the
+// courses with a final grade, they all weigh the same. Failed and disqualified courses,
+// and pending ones whose average so far is below the passing grade or that can no
+// longer be passed, are at risk.
+func (r StatusRules) Summarize(semester string, courses []gosuv2.SuvCurrentCourseGrades, catalog Catalog) Summary {
+	summary := Summary{Semester: semester, Courses: len(courses), MissingCredits: []int{}, AtRisk: CourseRisks{}}
+
+	type finished struct {
+		grade   float32
+		credits float32
+	}
+	var graded []finished
+
+	for _, course := range courses {
+		status := r.Evaluate(course)
+		projection := r.Project(course, nil)
+
+		switch status.Status {
+		case StatusPassed:
+			summary.Passed++
+		case StatusFailed:
+			summary.Failed++
+		default:
+			summary.Pending++
+		}
+		if course.Disabled {
+			summary.Disqualified++
+		}
+
+		credits, ok := catalog[course.CourseID]
+		if !ok {
+			summary.MissingCredits = append(summary.MissingCredits, course.CourseID)
+		}
+		if _, grade := finalGrade(course); status.Status != StatusPending && grade != 0 {
+			graded = append(graded, finished{grade, credits})
+		}
+
+		atRisk := status.Status == StatusFailed ||
+			status.Status == StatusPending && (!projection.Reachable || projection.Average != 0 && projection.Average < r.MinimumGrade())
+		if atRisk {
+			summary.AtRisk = append(summary.AtRisk, CourseRisk{course.CourseID, course.CourseName, projection.Plan})
+		}
+	}
+
+	for _, course := range graded {
+		if course.credits > 0 {
+			summary.Weighted = true
+		}
+	}
+
+	var sum, weight float32
+	for _, course := range graded {
+		credits := course.credits
+		if !summary.Weighted {
+			credits = 1
+		}
+		sum += course.grade * credits
+		weight += credits
+	}
+	if weight > 0 {
+		summary.Average = roundHundredths(sum / weight)
+	}
+	if summary.Weighted {
+		summary.Credits = weight
+	}
+
+	return summary
+}
+
+// describe returns the summary as names and values, for text and table output, colored
+// by theme
+func (s Summary) describe(theme Theme) [][2]tableCell {
+	counts := fmt.Sprintf("%d passed, %d failed, %d pending", s.Passed, s.Failed, s.Pending)
+	if s.Disqualified > 0 {
+		counts += fmt.Sprintf(", %d disqualified", s.Disqualified)
+	}
+
+	average := formatGradeValue(s.Average)
+	switch {
+	case s.Average == 0:
+	case s.Weighted:
+		average += fmt.Sprintf(" over %g credits", s.Credits)
+	default:
+		average += " (no credits in the catalog, every course weighs the same)"
+	}
+
+	rows := [][2]tableCell{
+		{{Text: "Period"}, {Text: s.Semester}},
+		{{Text: "Courses"}, {Text: fmt.Sprintf("%d (%s)", s.Courses, counts)}},
+		{{Text: "Average"}, {Text: average, Color: gradeValueColor(theme, s.Average)}},
+	}
+	if len(s.MissingCredits) > 0 && s.Weighted {
+		rows = append(rows, [2]tableCell{{Text: "Without credits"}, {Text: formatRecordValue(s.MissingCredits)}})
+	}
+	for _, risk := range s.AtRisk {
+		rows = append(rows, [2]tableCell{{Text: "At risk"}, {Text: fmt.Sprintf("%d %s: %s", risk.CourseID, risk.CourseName, risk.Plan)}})
+	}
+	return rows
+}
@@ -0,0 +1,116 @@
+package suvtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/patitolabs/gosuv2"
+	"github.com/patitolabs/suvctl/util"
+)
+
+// Backend is a util.Backend serving Fixtures from memory
+type Backend struct {
+	Fixtures *Fixtures
+
+	// Err, when set, is returned by every call to simulate a network failure
+	Err error
+
+	sessions sessions
+	session  string
+}
+
+// NewBackend creates a Backend serving fixtures
+func NewBackend(fixtures *Fixtures) *Backend {
+	return &Backend{Fixtures: fixtures}
+}
+
+// Expire invalidates every session handed out so far, as SUV does when they time out
+func (b *Backend) Expire() {
+	b.sessions.expire()
+}
+
+func (b *Backend) Login(ctx context.Context, usercode, password string) (string, error) {
+	if err := b.check(ctx, false); err != nil {
+		return "", err
+	}
+
+	session, ok := b.sessions.login(b.Fixtures, usercode, password)
+	if !ok {
+		return "", util.Errorf(util.KindAuth, "login failed, check your credentials")
+	}
+
+	b.session = session
+	return session, nil
+}
+
+func (b *Backend) Logout(ctx context.Context) error {
+	if err := b.check(ctx, false); err != nil {
+		return err
+	}
+
+	if !b.sessions.logout(b.session) {
+		return errors.New("logout failed: unexpected response")
+	}
+
+	b.session = ""
+	return nil
+}
+
+func (b *Backend) LoadPhpSession(session string) error {
+	if session == "" {
+		return errors.New("php session is empty")
+	}
+
+	b.session = session
+	return nil
+}
+
+func (b *Backend) GetSuvGradesResponse(ctx context.Context) (*gosuv2.SuvGradesResponse, error) {
+	if err := b.check(ctx, true); err != nil {
+		return nil, err
+	}
+	return b.Fixtures.GradesResponse(), nil
+}
+
+func (b *Backend) SearchStudentByCode(ctx context.Context, code string) ([]gosuv2.StudentBasicResponse, error) {
+	if err := b.check(ctx, true); err != nil {
+		return nil, err
+	}
+	return b.Fixtures.StudentsByCode(code), nil
+}
+
+func (b *Backend) SearchStudentByName(ctx context.Context, name, lastname string) ([]gosuv2.StudentBasicResponse, error) {
+	if err := b.check(ctx, true); err != nil {
+		return nil, err
+	}
+	return b.Fixtures.StudentsByName(name, lastname), nil
+}
+
+func (b *Backend) SearchStudentByDni(ctx context.Context, dni string) ([]gosuv2.StudentBasicResponse, error) {
+	if err := b.check(ctx, true); err != nil {
+		return nil, err
+	}
+	return b.Fixtures.StudentsByDni(dni), nil
+}
+
+func (b *Backend) SearchProfessor(ctx context.Context, name, lastname string) ([]gosuv2.ProfessorBasicResponse, error) {
+	if err := b.check(ctx, true); err != nil {
+		return nil, err
+	}
+	return b.Fixtures.ProfessorsByName(name, lastname), nil
+}
+
+// check returns the error a call would fail with, if any
+func (b *Backend) check(ctx context.Context, needsSession bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if b.Err != nil {
+		return b.Err
+	}
+	if needsSession && !b.sessions.isValid(b.session) {
+		return fmt.Errorf("%w: the stand-in does not know session %q", util.ErrSessionExpired, b.session)
+	}
+	return nil
+}
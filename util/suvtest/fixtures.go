@@ -0,0 +1,191 @@
+// Package suvtest provides stand-ins for SUV2 to exercise suvctl offline: a Backend
+// implementation working in memory and an HTTP server speaking the SUV2 protocol, both
+// fed by the same fixtures.
+package suvtest
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/patitolabs/gosuv2"
+)
+
+//go:embed fixtures/*.json
+var defaultFixtures embed.FS
+
+// Fixtures holds the data served by the stand-ins
+type Fixtures struct {
+	// Users maps the user codes that can log in to their passwords
+	Users      map[string]string
+	Grades     GradesFixture
+	Students   []gosuv2.StudentBasicResponse
+	Professors []gosuv2.ProfessorBasicResponse
+}
+
+// GradesFixture holds the current period as sent by SUV2, with the courses in its wire
+// format so that fixtures can be captured from a real response
+type GradesFixture struct {
+	PaymentStatus  string           `json:"payment_status"`
+	EnrollmentType string           `json:"enrollment_type"`
+	Semester       string           `json:"semester"`
+	Courses        []map[string]any `json:"courses"`
+}
+
+// DefaultFixtures returns the fixtures bundled with the package
+func DefaultFixtures() *Fixtures {
+	fixtures, err := loadFixtures(defaultFixtures, "fixtures")
+	if err != nil {
+		panic(err)
+	}
+	return fixtures
+}
+
+// LoadFixtures reads users.json, grades.json, students.json and professors.json from dir
+func LoadFixtures(dir string) (*Fixtures, error) {
+	return loadFixtures(os.DirFS(dir), ".")
+}
+
+func loadFixtures(fsys fs.FS, dir string) (*Fixtures, error) {
+	fixtures := &Fixtures{}
+
+	files := map[string]any{
+		"users.json":      &fixtures.Users,
+		"grades.json":     &fixtures.Grades,
+		"students.json":   &fixtures.Students,
+		"professors.json": &fixtures.Professors,
+	}
+
+	for name, target := range files {
+		data, err := fs.ReadFile(fsys, dir+"/"+name)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, target); err != nil {
+			return nil, err
+		}
+	}
+
+	return fixtures, nil
+}
+
+// GradesResponse returns the grades fixture as parsed by gosuv2
+func (f *Fixtures) GradesResponse() *gosuv2.SuvGradesResponse {
+	response := &gosuv2.SuvGradesResponse{
+		PaymentStatus:  f.Grades.PaymentStatus,
+		EnrollmentType: f.Grades.EnrollmentType,
+		Semester:       f.Grades.Semester,
+		Courses:        make([]gosuv2.SuvCurrentCourseGrades, 0, len(f.Grades.Courses)),
+	}
+
+	for _, course := range f.Grades.Courses {
+		response.Courses = append(response.Courses, parseCourse(course))
+	}
+
+	return response
+}
+
+// parseCourse converts a course from the SUV2 wire format, where every value is a string,
+// the same way gosuv2 does
+func parseCourse(raw map[string]any) gosuv2.SuvCurrentCourseGrades {
+	text := func(key string) string {
+		value, _ := raw[key].(string)
+		return value
+	}
+	integer := func(key string) int {
+		value, _ := strconv.Atoi(text(key))
+		return value
+	}
+	grade := func(key string) float32 {
+		value, _ := strconv.ParseFloat(text(key), 32)
+		return float32(value)
+	}
+
+	course := gosuv2.SuvCurrentCourseGrades{
+		CourseID:     integer("idcurso"),
+		CourseName:   text("curso"),
+		Attempt:      integer("vez"),
+		Average1:     grade("promedio1"),
+		Average2:     grade("promedio2"),
+		Average3:     grade("promedio3"),
+		Average4:     grade("promedio4"),
+		Average5:     grade("promedio5"),
+		Average6:     grade("promedio6"),
+		Substitute:   grade("sustitutorio"),
+		Average:      grade("promedio"),
+		Postponed:    grade("aplazado"),
+		FinalAverage: grade("pfinal"),
+		Disabled:     integer("inh") == 1,
+		FinalStatus:  integer("estado_final"),
+	}
+
+	if weights, ok := raw["pesos"].([]any); ok {
+		course.Weights = make([]float32, len(weights))
+		for i, weight := range weights {
+			text, _ := weight.(string)
+			value, _ := strconv.ParseFloat(text, 32)
+			course.Weights[i] = float32(value)
+		}
+	}
+
+	if statuses, ok := raw["estados"].([]any); ok {
+		course.Statuses = make([]int, len(statuses))
+		for i, status := range statuses {
+			text, _ := status.(string)
+			course.Statuses[i], _ = strconv.Atoi(text)
+		}
+	}
+
+	return course
+}
+
+// StudentsByCode returns the students whose code is code
+func (f *Fixtures) StudentsByCode(code string) []gosuv2.StudentBasicResponse {
+	students := []gosuv2.StudentBasicResponse{}
+	for _, student := range f.Students {
+		if student.StudentID == code {
+			students = append(students, student)
+		}
+	}
+	return students
+}
+
+// StudentsByName returns the students whose name contains both name and lastname
+func (f *Fixtures) StudentsByName(name, lastname string) []gosuv2.StudentBasicResponse {
+	students := []gosuv2.StudentBasicResponse{}
+	for _, student := range f.Students {
+		if containsFold(student.StudentName, name) && containsFold(student.StudentName, lastname) {
+			students = append(students, student)
+		}
+	}
+	return students
+}
+
+// StudentsByDni returns the students whose DNI is dni
+func (f *Fixtures) StudentsByDni(dni string) []gosuv2.StudentBasicResponse {
+	students := []gosuv2.StudentBasicResponse{}
+	for _, student := range f.Students {
+		if student.DNI == dni {
+			students = append(students, student)
+		}
+	}
+	return students
+}
+
+// ProfessorsByName returns the professors whose name contains both name and lastname
+func (f *Fixtures) ProfessorsByName(name, lastname string) []gosuv2.ProfessorBasicResponse {
+	professors := []gosuv2.ProfessorBasicResponse{}
+	for _, professor := range f.Professors {
+		if containsFold(professor.ProfessorName, name) && containsFold(professor.ProfessorName, lastname) {
+			professors = append(professors, professor)
+		}
+	}
+	return professors
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToUpper(s), strings.ToUpper(substr))
+}
@@ -0,0 +1,146 @@
+package suvtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/patitolabs/gosuv2"
+	"github.com/patitolabs/suvctl/util"
+)
+
+// loginPage is what SUV2 answers instead of JSON when the session is not valid
+const loginPage = `<!DOCTYPE html><html><head><title>SUV2</title></head><body>
+<form action="validar.php" method="post">
+<input type="text" name="user"><input type="password" name="pass">
+<button type="submit">Ingresar</button>
+</form></body></html>`
+
+// Server is an HTTPS server speaking the subset of the SUV2 protocol used by gosuv2,
+// serving Fixtures. It uses TLS because gosuv2 always connects over https.
+type Server struct {
+	*httptest.Server
+	Fixtures *Fixtures
+
+	sessions sessions
+}
+
+// NewServer starts a Server serving fixtures. Callers must Close it.
+func NewServer(fixtures *Fixtures) *Server {
+	s := &Server{Fixtures: fixtures}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /validar.php", s.handleLogin)
+	mux.HandleFunc("GET /desconectar.php", s.handleLogout)
+	mux.HandleFunc("POST /controller/alumnoController.php", s.handleStudent)
+	mux.HandleFunc("POST /controller/buscarAlumnoController.php", s.handleStudentSearch)
+	mux.HandleFunc("POST /controller/buscarDocenteController.php", s.handleProfessorSearch)
+
+	s.Server = httptest.NewTLSServer(mux)
+	return s
+}
+
+// Host returns the host and port to set in gosuv2.SuvConfig
+func (s *Server) Host() string {
+	u, _ := url.Parse(s.URL)
+	return u.Host
+}
+
+// Backend returns a util.SuvBackend talking to the server, with detailed set as given
+func (s *Server) Backend(detailed bool) *util.SuvBackend {
+	config := &gosuv2.SuvConfig{Host: s.Host(), Detailed: detailed}
+	return util.NewSuvBackend(config, s.Client().Transport)
+}
+
+// Expire invalidates every session handed out so far, as SUV does when they time out
+func (s *Server) Expire() {
+	s.sessions.expire()
+}
+
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	session, ok := s.sessions.login(s.Fixtures, r.PostFormValue("user"), r.PostFormValue("pass"))
+	if !ok {
+		writeJSON(w, []string{"0", "0", "0", "0"})
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{Name: "PHPSESSID", Value: session, Path: "/"})
+	writeJSON(w, []string{"1", "1", "1", "1"})
+}
+
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if s.sessions.logout(sessionOf(r)) {
+		w.Write([]byte("<html><body>Su sesion ha culminado</body></html>"))
+		return
+	}
+	w.Write([]byte(loginPage))
+}
+
+func (s *Server) handleStudent(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(w, r) {
+		return
+	}
+
+	if r.PostFormValue("task") != "verNotasPeriodoActual" {
+		http.Error(w, "unknown task", http.StatusBadRequest)
+		return
+	}
+
+	grades := s.Fixtures.Grades
+	writeJSON(w, []any{grades.PaymentStatus, grades.EnrollmentType, grades.Courses, grades.Semester})
+}
+
+func (s *Server) handleStudentSearch(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(w, r) {
+		return
+	}
+
+	switch r.PostFormValue("task") {
+	case "buscarCodigo":
+		writeJSON(w, s.Fixtures.StudentsByCode(r.PostFormValue("codigo")))
+	case "buscarAlumno":
+		writeJSON(w, s.Fixtures.StudentsByName(r.PostFormValue("nombre"), r.PostFormValue("apellido")))
+	case "buscarDNI":
+		writeJSON(w, s.Fixtures.StudentsByDni(r.PostFormValue("dni")))
+	default:
+		http.Error(w, "unknown task", http.StatusBadRequest)
+	}
+}
+
+func (s *Server) handleProfessorSearch(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(w, r) {
+		return
+	}
+
+	if r.PostFormValue("task") != "buscarDocente" {
+		http.Error(w, "unknown task", http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, s.Fixtures.ProfessorsByName(r.PostFormValue("nombre"), r.PostFormValue("apellido")))
+}
+
+// authorized answers with the login page, as SUV2 does, unless the request has a valid session
+func (s *Server) authorized(w http.ResponseWriter, r *http.Request) bool {
+	if s.sessions.isValid(sessionOf(r)) {
+		return true
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	w.Write([]byte(loginPage))
+	return false
+}
+
+func sessionOf(r *http.Request) string {
+	cookie, err := r.Cookie("PHPSESSID")
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+func writeJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,59 @@
+package suvtest
+
+import (
+	"fmt"
+	"sync"
+)
+
+// sessions tracks the sessions handed out by a stand-in
+type sessions struct {
+	mu    sync.Mutex
+	valid map[string]bool
+	next  int
+}
+
+// login returns a new session when password is the one of usercode in fixtures
+func (s *sessions) login(fixtures *Fixtures, usercode, password string) (string, bool) {
+	expected, ok := fixtures.Users[usercode]
+	if !ok || expected != password {
+		return "", false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.valid == nil {
+		s.valid = map[string]bool{}
+	}
+
+	s.next++
+	session := fmt.Sprintf("suvtest%08d", s.next)
+	s.valid[session] = true
+
+	return session, true
+}
+
+func (s *sessions) isValid(session string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.valid[session]
+}
+
+func (s *sessions) logout(session string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.valid[session] {
+		return false
+	}
+	delete(s.valid, session)
+	return true
+}
+
+func (s *sessions) expire() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.valid = map[string]bool{}
+}
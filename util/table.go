@@ -0,0 +1,408 @@
+package util
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/rivo/uniseg"
+	"golang.org/x/term"
+)
+
+// ellipsis marks the cells cut to fit their column
+const ellipsis = "…"
+
+// Alignment is the horizontal alignment of the cells of a column
+type Alignment string
+
+const (
+	AlignLeft   Alignment = "left"
+	AlignRight  Alignment = "right"
+	AlignCenter Alignment = "center"
+)
+
+// BorderStyle is the set of characters a table is drawn with
+type BorderStyle string
+
+const (
+	BorderRounded  BorderStyle = "rounded"
+	BorderASCII    BorderStyle = "ascii"
+	BorderNone     BorderStyle = "none"
+	BorderMarkdown BorderStyle = "markdown"
+)
+
+// Column defines a column of a Table
+type Column struct {
+	Name  string
+	Align Alignment
+	// MinWidth and MaxWidth bound the width of the cells, 0 means no bound
+	MinWidth int
+	MaxWidth int
+	// Wrap makes cells wider than the column span several lines instead of being truncated
+	Wrap bool
+	// Format turns a value into the text of its cell, fmt.Sprint is used when nil
+	Format func(value any) string
+	// Color returns the color of the cell of a value in the theme of the table
+	Color func(theme Theme, value any) Color
+}
+
+// Table renders rows of values as a table, sizing each column to fit its cells
+type Table struct {
+	Columns []Column
+	Border  BorderStyle
+	// Width is the number of terminal cells the table has to fit in, 0 means no limit
+	Width int
+	// Color enables the colors of the cells, which Theme gives
+	Color bool
+	Theme Theme
+
+	rows [][]any
+}
+
+// tableCell is the text of a table cell and the color to print it with.
+// A tableCell given as a row value is printed as is, skipping the column formatter.
+type tableCell struct {
+	Text  string
+	Color Color
+}
+
+// borderChars holds the characters of a border style. The junctions of a horizontal
+// line are its left, middle and right characters, nil ones skip the line.
+type borderChars struct {
+	top, separator, bottom []string
+	horizontal             string
+	vertical               []string
+	padding                int
+}
+
+var borderStyles = map[BorderStyle]borderChars{
+	BorderRounded: {
+		top:        []string{"╭", "┬", "╮"},
+		separator:  []string{"├", "┼", "┤"},
+		bottom:     []string{"╰", "┴", "╯"},
+		horizontal: "─",
+		vertical:   []string{"│", "│", "│"},
+		padding:    1,
+	},
+	BorderASCII: {
+		top:        []string{"+", "+", "+"},
+		separator:  []string{"+", "+", "+"},
+		bottom:     []string{"+", "+", "+"},
+		horizontal: "-",
+		vertical:   []string{"|", "|", "|"},
+		padding:    1,
+	},
+	BorderNone: {
+		vertical: []string{"", "  ", ""},
+	},
+	BorderMarkdown: {
+		vertical: []string{"|", "|", "|"},
+		padding:  1,
+	},
+}
+
+// parseTableBorder returns the table border style named border, rounded when empty
+func parseTableBorder(border string) (BorderStyle, error) {
+	if border == "" {
+		return BorderRounded, nil
+	}
+	if _, ok := borderStyles[BorderStyle(border)]; !ok {
+		return "", Errorf(KindUsage, "unknown table border %q (use rounded, ascii, none or markdown)", border)
+	}
+	return BorderStyle(border), nil
+}
+
+// NewTable returns a table with the given columns, the border, colors and theme of opts
+// and the width of w if it is a terminal
+func NewTable(w io.Writer, opts OutputOptions, columns ...Column) *Table {
+	return &Table{
+		Columns: columns,
+		Border:  opts.Border,
+		Width:   terminalWidth(w),
+		Color:   ColorEnabled(w, opts.Color),
+		Theme:   opts.Theme,
+	}
+}
+
+// AddRow appends a row with one value per column
+func (t *Table) AddRow(values ...any) {
+	t.rows = append(t.rows, values)
+}
+
+// Render writes the table to w
+func (t *Table) Render(w io.Writer) error {
+	border := t.Border
+	if border == "" {
+		border = BorderRounded
+	}
+	chars, ok := borderStyles[border]
+	if !ok {
+		return fmt.Errorf("unknown table border %q", border)
+	}
+	markdown := border == BorderMarkdown
+
+	header := make([]tableCell, len(t.Columns))
+	for i, col := range t.Columns {
+		header[i] = tableCell{Text: col.Name}
+	}
+
+	rows := make([][]tableCell, len(t.rows))
+	for i, values := range t.rows {
+		rows[i] = make([]tableCell, len(t.Columns))
+		for j, col := range t.Columns {
+			var value any
+			if j < len(values) {
+				value = values[j]
+			}
+			cell := col.cell(t.Theme, value)
+			if markdown {
+				// Markdown tables are documents, keep them free of escape sequences
+				cell = tableCell{Text: strings.ReplaceAll(cell.Text, "|", `\|`)}
+			}
+			rows[i][j] = cell
+		}
+	}
+
+	widths := t.columnWidths(header, rows, chars, !markdown)
+
+	printTableLine(w, chars.top, chars.horizontal, widths, chars.padding)
+	t.printRow(w, header, widths, chars)
+	if markdown {
+		t.printMarkdownSeparator(w, widths)
+	} else {
+		printTableLine(w, chars.separator, chars.horizontal, widths, chars.padding)
+	}
+	for _, row := range rows {
+		t.printRow(w, row, widths, chars)
+	}
+	printTableLine(w, chars.bottom, chars.horizontal, widths, chars.padding)
+
+	return nil
+}
+
+// cell formats a value of the column
+func (col Column) cell(theme Theme, value any) tableCell {
+	switch value := value.(type) {
+	case nil:
+		return tableCell{}
+	case tableCell:
+		return value
+	}
+
+	cell := tableCell{}
+	if col.Format != nil {
+		cell.Text = col.Format(value)
+	} else {
+		cell.Text = fmt.Sprint(value)
+	}
+	if col.Color != nil {
+		cell.Color = col.Color(theme, value)
+	}
+	return cell
+}
+
+// columnWidths sizes each column to its widest cell within its bounds and, if fit is
+// set, shrinks the widest columns until the table fits in its width
+func (t *Table) columnWidths(header []tableCell, rows [][]tableCell, chars borderChars, fit bool) []int {
+	widths := make([]int, len(t.Columns))
+	for i, col := range t.Columns {
+		width := displayWidth(header[i].Text)
+		for _, row := range rows {
+			width = max(width, displayWidth(row[i].Text))
+		}
+		if fit && col.MaxWidth > 0 {
+			width = min(width, col.MaxWidth)
+		}
+		widths[i] = max(width, col.MinWidth)
+	}
+
+	if !fit || t.Width <= 0 || len(widths) == 0 {
+		return widths
+	}
+
+	overhead := displayWidth(chars.vertical[0]) + displayWidth(chars.vertical[2]) +
+		(len(widths)-1)*displayWidth(chars.vertical[1]) + len(widths)*2*chars.padding
+	total := overhead
+	for _, width := range widths {
+		total += width
+	}
+
+	for total > t.Width {
+		widest := -1
+		for i, width := range widths {
+			if width > max(t.Columns[i].MinWidth, 1) && (widest < 0 || width > widths[widest]) {
+				widest = i
+			}
+		}
+		if widest < 0 {
+			break
+		}
+		widths[widest]--
+		total--
+	}
+
+	return widths
+}
+
+// printRow prints a row of cells, spanning as many lines as its tallest wrapped cell
+func (t *Table) printRow(w io.Writer, row []tableCell, widths []int, chars borderChars) {
+	lines := make([][]string, len(row))
+	height := 1
+	for i, cell := range row {
+		if t.Columns[i].Wrap {
+			lines[i] = wrapWidth(cell.Text, widths[i])
+		} else {
+			lines[i] = []string{truncateWidth(cell.Text, widths[i])}
+		}
+		height = max(height, len(lines[i]))
+	}
+
+	colors := Colorizer{enabled: t.Color}
+	pad := strings.Repeat(" ", chars.padding)
+	for line := range height {
+		var b strings.Builder
+		b.WriteString(chars.vertical[0])
+		for i, cell := range row {
+			if i > 0 {
+				b.WriteString(chars.vertical[1])
+			}
+			text := ""
+			if line < len(lines[i]) {
+				text = lines[i][line]
+			}
+			left, right := alignCell(text, widths[i], t.Columns[i].Align)
+			b.WriteString(pad + left)
+			b.WriteString(colors.Paint(cell.Color, text))
+			b.WriteString(right + pad)
+		}
+		b.WriteString(chars.vertical[2])
+		fmt.Fprintln(w, strings.TrimRight(b.String(), " "))
+	}
+}
+
+func (t *Table) printMarkdownSeparator(w io.Writer, widths []int) {
+	fmt.Fprint(w, "|")
+	for i, width := range widths {
+		dashes := strings.Repeat("-", width)
+		switch t.Columns[i].Align {
+		case AlignRight:
+			fmt.Fprintf(w, " %s:|", dashes)
+		case AlignCenter:
+			fmt.Fprintf(w, ":%s:|", dashes)
+		default:
+			fmt.Fprintf(w, " %s |", dashes)
+		}
+	}
+	fmt.Fprintln(w)
+}
+
+// printTableLine prints a horizontal line of the table, if the border style has it
+func printTableLine(w io.Writer, junctions []string, horizontal string, widths []int, padding int) {
+	if junctions == nil {
+		return
+	}
+
+	fmt.Fprint(w, junctions[0])
+	for i, width := range widths {
+		fmt.Fprint(w, strings.Repeat(horizontal, width+2*padding))
+		if i < len(widths)-1 {
+			fmt.Fprint(w, junctions[1])
+		}
+	}
+	fmt.Fprintln(w, junctions[2])
+}
+
+// alignCell returns the padding to put on each side of text to align it in width
+// terminal cells
+func alignCell(text string, width int, align Alignment) (left, right string) {
+	padding := max(width-displayWidth(text), 0)
+
+	switch align {
+	case AlignCenter:
+		return strings.Repeat(" ", padding/2), strings.Repeat(" ", padding-padding/2)
+	case AlignRight:
+		return strings.Repeat(" ", padding), ""
+	default:
+		return "", strings.Repeat(" ", padding)
+	}
+}
+
+// terminalWidth returns the width of w if it is a terminal, 0 otherwise
+func terminalWidth(w io.Writer) int {
+	f, ok := w.(*os.File)
+	if !ok {
+		return 0
+	}
+
+	width, _, err := term.GetSize(int(f.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// displayWidth returns the number of terminal cells s takes, counting East Asian wide
+// characters as two and combining marks as none
+func displayWidth(s string) int {
+	return uniseg.StringWidth(s)
+}
+
+// truncateWidth shortens s to at most width terminal cells, ending it with an ellipsis
+// when cut, without splitting a grapheme cluster
+func truncateWidth(s string, width int) string {
+	if displayWidth(s) <= width {
+		return s
+	}
+	if width <= 0 {
+		return ""
+	}
+
+	head, _ := cutWidth(s, width-1)
+	return strings.TrimRight(head, " ") + ellipsis
+}
+
+// wrapWidth splits s into lines of at most width terminal cells, breaking at spaces
+// and splitting words longer than a line
+func wrapWidth(s string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		if line != "" && displayWidth(line)+1+displayWidth(word) <= width {
+			line += " " + word
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+		for displayWidth(word) > width {
+			head, rest := cutWidth(word, width)
+			if head == "" {
+				break
+			}
+			lines = append(lines, head)
+			word = rest
+		}
+		line = word
+	}
+	if line != "" || len(lines) == 0 {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// cutWidth splits s after as many grapheme clusters as fit in width terminal cells
+func cutWidth(s string, width int) (head, rest string) {
+	used := 0
+	state := -1
+	rest = s
+	for rest != "" {
+		_, next, clusterWidth, newState := uniseg.FirstGraphemeClusterInString(rest, state)
+		if used+clusterWidth > width {
+			break
+		}
+		used += clusterWidth
+		rest, state = next, newState
+	}
+	return s[:len(s)-len(rest)], rest
+}
@@ -0,0 +1,49 @@
+package util_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/patitolabs/suvctl/util"
+)
+
+func newTestTable(border util.BorderStyle, width int) *util.Table {
+	table := &util.Table{
+		Columns: []util.Column{
+			{Name: "Code", Align: util.AlignRight},
+			{Name: "Name", Wrap: true},
+			{Name: "Note", MaxWidth: 12},
+			{Name: "State", Align: util.AlignCenter},
+		},
+		Border: border,
+		Width:  width,
+	}
+	table.AddRow(1, "ÑANDÚ | 日本語テキスト", "a rather long note", "ok")
+	table.AddRow(22, "LUIS", nil, "pending")
+	return table
+}
+
+func TestTableGolden(t *testing.T) {
+	cases := []struct {
+		name   string
+		border util.BorderStyle
+		width  int
+	}{
+		{"rounded", util.BorderRounded, 0},
+		{"ascii", util.BorderASCII, 0},
+		{"none", util.BorderNone, 0},
+		{"markdown", util.BorderMarkdown, 0},
+		{"rounded-narrow", util.BorderRounded, 40},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := newTestTable(tc.border, tc.width).Render(&buf); err != nil {
+				t.Fatal(err)
+			}
+
+			assertGolden(t, "table-"+tc.name, buf.Bytes())
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package util
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// templateFuncs returns the helpers available to --output template, on top of the
+// text/template builtins, painting text with colors
+func templateFuncs(colors Colorizer) template.FuncMap {
+	return template.FuncMap{
+		// grade formats a grade with two decimals, or "-" when there is none
+		"grade": func(value any) (string, error) {
+			grade, err := toFloat(value)
+			if err != nil {
+				return "", err
+			}
+			return formatGradeValue(float32(grade)), nil
+		},
+		// number formats a number with the given decimals
+		"number": func(decimals int, value any) (string, error) {
+			number, err := toFloat(value)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("%.*f", decimals, number), nil
+		},
+		// padLeft and padRight pad a value with spaces up to the given display width
+		"padLeft": func(width int, value any) string {
+			text := fmt.Sprint(value)
+			left, _ := alignCell(text, width, AlignRight)
+			return left + text
+		},
+		"padRight": func(width int, value any) string {
+			text := fmt.Sprint(value)
+			_, right := alignCell(text, width, AlignLeft)
+			return text + right
+		},
+		// truncate cuts a value to the given display width, ending it with an ellipsis
+		"truncate": func(width int, value any) string {
+			return truncateWidth(fmt.Sprint(value), width)
+		},
+		// status colors a final status, gradeColor colors a formatted grade by its value
+		"status": func(status string) string {
+			return colors.Paint(colors.theme.StatusColor(status), status)
+		},
+		"gradeColor": func(value any) (string, error) {
+			grade, err := toFloat(value)
+			if err != nil {
+				return "", err
+			}
+			return colors.Paint(gradeValueColor(colors.theme, float32(grade)), formatGradeValue(float32(grade))), nil
+		},
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+	}
+}
+
+// templateSource returns the template given with --output template=TEXT or
+// --output template-file=PATH
+func templateSource(opts OutputOptions) (string, error) {
+	if opts.Template == "" {
+		return "", Errorf(KindUsage, "template output needs a template, use --output 'template={{range .}}...{{end}}' or --output template-file=PATH")
+	}
+
+	if opts.Format == OutputTemplateFile {
+		text, err := os.ReadFile(opts.Template)
+		if err != nil {
+			return "", fmt.Errorf("error reading template: %w", err)
+		}
+		return string(text), nil
+	}
+
+	return opts.Template, nil
+}
+
+// outputTemplate runs the template of opts over data
+func outputTemplate(w io.Writer, data any, opts OutputOptions) error {
+	source, err := templateSource(opts)
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := template.New("output").Funcs(templateFuncs(NewColorizer(w, opts.Color, opts.Theme))).Parse(source)
+	if err != nil {
+		return Errorf(KindUsage, "error parsing template: %w", err)
+	}
+
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("error executing template: %w", err)
+	}
+	return nil
+}
+
+func toFloat(value any) (float64, error) {
+	switch value := value.(type) {
+	case float32:
+		return float64(value), nil
+	case float64:
+		return value, nil
+	case int:
+		return float64(value), nil
+	default:
+		return 0, fmt.Errorf("%v is not a number", value)
+	}
+}
@@ -0,0 +1,160 @@
+package util
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Theme holds the colors used for grades and statuses in every output format
+type Theme struct {
+	Passed  Color `mapstructure:"passed"`
+	Failed  Color `mapstructure:"failed"`
+	Pending Color `mapstructure:"pending"`
+	Warning Color `mapstructure:"warning"`
+	// Bands color grades by range, from the lowest to the highest one
+	Bands []GradeBand `mapstructure:"bands"`
+}
+
+// GradeBand colors the grades below a value, or every remaining grade when Below is nil
+type GradeBand struct {
+	Below *float32 `mapstructure:"below"`
+	Color Color    `mapstructure:"color"`
+}
+
+// themeConfig is the theme section of config.yml
+type themeConfig struct {
+	Preset string      `mapstructure:"preset"`
+	Colors Theme       `mapstructure:"colors"`
+	Bands  []GradeBand `mapstructure:"bands"`
+}
+
+func below(grade float32) *float32 {
+	return &grade
+}
+
+// ThemePresets are the themes that can be chosen with theme.preset
+var ThemePresets = map[string]Theme{
+	"default": {
+		Passed:  ColorGreen,
+		Failed:  ColorRed,
+		Pending: ColorYellow,
+		Warning: ColorRed,
+		Bands: []GradeBand{
+			{Below: below(10.5), Color: ColorRed},
+			{Below: below(13.5), Color: ColorYellow},
+			{Color: ColorLightBlue},
+		},
+	},
+	// The Okabe-Ito palette, which stays distinguishable with every kind of color blindness
+	"colorblind": {
+		Passed:  "#0072b2",
+		Failed:  "#d55e00",
+		Pending: "#e69f00",
+		Warning: "#d55e00",
+		Bands: []GradeBand{
+			{Below: below(10.5), Color: "#d55e00"},
+			{Below: below(13.5), Color: "#e69f00"},
+			{Color: "#0072b2"},
+		},
+	},
+	"none": {},
+}
+
+// GetTheme returns the theme from the theme section of viper config: a preset, colors
+// replacing some of its status colors and bands replacing its grade bands
+func GetTheme() (Theme, error) {
+	var config themeConfig
+	if err := viper.UnmarshalKey("theme", &config); err != nil {
+		return Theme{}, Errorf(KindUsage, "invalid theme: %w", err)
+	}
+
+	if config.Preset == "" {
+		config.Preset = "default"
+	}
+	theme, ok := ThemePresets[config.Preset]
+	if !ok {
+		return Theme{}, Errorf(KindUsage, "unknown theme preset %q (use %s)", config.Preset, strings.Join(themePresetNames(), ", "))
+	}
+
+	for _, color := range []struct{ value, override *Color }{
+		{&theme.Passed, &config.Colors.Passed},
+		{&theme.Failed, &config.Colors.Failed},
+		{&theme.Pending, &config.Colors.Pending},
+		{&theme.Warning, &config.Colors.Warning},
+	} {
+		if *color.override != "" {
+			*color.value = *color.override
+		}
+	}
+	if config.Bands != nil {
+		theme.Bands = config.Bands
+	}
+
+	return theme, theme.Validate()
+}
+
+// Validate checks the colors of the theme and that its bands go from the lowest to the
+// highest grades
+func (t Theme) Validate() error {
+	for _, color := range []Color{t.Passed, t.Failed, t.Pending, t.Warning} {
+		if err := color.Validate(); err != nil {
+			return Errorf(KindUsage, "invalid theme: %w", err)
+		}
+	}
+
+	for i, band := range t.Bands {
+		if err := band.Color.Validate(); err != nil {
+			return Errorf(KindUsage, "invalid theme band %d: %w", i+1, err)
+		}
+		if band.Below == nil && i < len(t.Bands)-1 {
+			return Errorf(KindUsage, "invalid theme band %d: only the last band can leave out below", i+1)
+		}
+		if i > 0 && band.Below != nil && *band.Below <= *t.Bands[i-1].Below {
+			return Errorf(KindUsage, "invalid theme band %d: bands must go from the lowest to the highest grades", i+1)
+		}
+	}
+	return nil
+}
+
+// GradeColor returns the color of the band grade falls in
+func (t Theme) GradeColor(grade float32) Color {
+	for _, band := range t.Bands {
+		if band.Below == nil || grade < *band.Below {
+			return band.Color
+		}
+	}
+	return ColorDefault
+}
+
+// gradeValueTheme returns the color of a grade in theme, the default one when there is none
+func gradeValueTheme(theme Theme, grade float32) Color {
+	if grade == 0 {
+		return ColorDefault
+	}
+	return theme.GradeColor(grade)
+}
+
+// StatusColor returns the color of a final status
+func (t Theme) StatusColor(status string) Color {
+	switch strings.ToUpper(status) {
+	case "PASSED":
+		return t.Passed
+	case "FAILED":
+		return t.Failed
+	case "PENDING":
+		return t.Pending
+	default:
+		return ColorDefault
+	}
+}
+
+func themePresetNames() []string {
+	names := make([]string, 0, len(ThemePresets))
+	for name := range ThemePresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,86 @@
+package util_test
+
+import (
+	"testing"
+
+	"github.com/patitolabs/suvctl/util"
+	"github.com/spf13/viper"
+)
+
+func TestTheme(t *testing.T) {
+	t.Cleanup(func() { viper.Set("theme", nil) })
+
+	viper.Set("theme", map[string]any{
+		"preset": "colorblind",
+		"colors": map[string]any{"pending": "208"},
+		"bands": []any{
+			map[string]any{"below": 10.5, "color": "red"},
+			map[string]any{"below": 13.5, "color": "yellow"},
+			map[string]any{"color": "blue"},
+		},
+	})
+
+	theme, err := util.GetTheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for grade, want := range map[float32]util.Color{10: "red", 10.5: "yellow", 13.4: "yellow", 13.5: "blue", 20: "blue"} {
+		if got := theme.GradeColor(grade); got != want {
+			t.Errorf("GradeColor(%v) = %q, want %q", grade, got, want)
+		}
+	}
+	if got := theme.StatusColor("passed"); got != "#0072b2" {
+		t.Errorf("StatusColor(passed) = %q, want the colorblind preset", got)
+	}
+	if got := theme.StatusColor("PENDING"); got != "208" {
+		t.Errorf("StatusColor(PENDING) = %q, want the configured color", got)
+	}
+
+	invalid := []map[string]any{
+		{"preset": "neon"},
+		{"colors": map[string]any{"failed": "#12345"}},
+		{"bands": []any{map[string]any{"color": "red"}, map[string]any{"below": 10, "color": "blue"}}},
+		{"bands": []any{map[string]any{"below": 13, "color": "red"}, map[string]any{"below": 10, "color": "blue"}}},
+	}
+	for _, config := range invalid {
+		viper.Set("theme", config)
+		if _, err := util.GetTheme(); util.KindOf(err) != util.KindUsage {
+			t.Errorf("expected a usage error for theme %v, got %v", config, err)
+		}
+	}
+
+	defaults := util.ThemePresets["default"]
+	for grade, want := range map[float32]util.Color{10.4: util.ColorRed, 10.5: util.ColorYellow, 13.4: util.ColorYellow, 13.5: util.ColorLightBlue} {
+		if got := defaults.GradeColor(grade); got != want {
+			t.Errorf("default GradeColor(%v) = %q, want %q", grade, got, want)
+		}
+	}
+}
+
+func TestColor(t *testing.T) {
+	cases := []struct {
+		color util.Color
+		css   string
+	}{
+		{"", ""},
+		{"red", "#c62828"},
+		{"light-blue", "#1565c0"},
+		{"#0072B2", "#0072b2"},
+		{"208", "#ff8700"},
+	}
+	for _, tc := range cases {
+		if err := tc.color.Validate(); err != nil {
+			t.Errorf("%q: %v", tc.color, err)
+		}
+		if css := tc.color.CSS(); css != tc.css {
+			t.Errorf("%q.CSS() = %q, want %q", tc.color, css, tc.css)
+		}
+	}
+
+	for _, bad := range []util.Color{"purple-ish", "256", "#zzzzzz"} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("expected an error for color %q", bad)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/patitolabs/gosuv2"
+)
+
+// minRetryDelay is the longest a watch waits before retrying after its first failure, the
+// delay doubling with every failure after that
+const minRetryDelay = time.Minute
+
+// WatchOptions configures Client.Watch
+type WatchOptions struct {
+	// Interval is how long to wait between polls
+	Interval time.Duration
+
+	// MaxBackoff caps the delay between retries while polls keep failing
+	MaxBackoff time.Duration
+
+	Filter GradeFilter
+
+	// OnChanges is called with what changed in the courses selected by Filter every time
+	// a poll finds something, an error stopping the watch
+	OnChanges func(changes []GradeChange) error
+
+	// OnError, when set, is called when a poll fails, with how long until the next one
+	OnError func(err error, retry time.Duration)
+}
+
+// Watch polls the grades until ctx is done, comparing every poll with the previous one.
+// The first poll only sets the grades to compare with. Failed polls are retried with an
+// exponential backoff with jitter, and an expired session is renewed as in every other
+// request; a session that cannot be renewed stops the watch with ErrSessionExpired.
+func (c *Client) Watch(ctx context.Context, opts WatchOptions) error {
+	var previous []gosuv2.SuvCurrentCourseGrades
+	polled := false
+	failures := 0
+
+	for {
+		wait := opts.Interval
+
+		suvGradesResponse, err := c.GradesResponse(ctx)
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case errors.Is(err, ErrSessionExpired):
+			return err
+		case err != nil:
+			failures++
+			wait = retryDelay(opts.Interval, opts.MaxBackoff, failures)
+			if opts.OnError != nil {
+				opts.OnError(err, wait)
+			}
+		default:
+			failures = 0
+			courses := suvGradesResponse.Courses
+
+			if !polled && !opts.Filter.empty() && !opts.Filter.matchesAny(courses) {
+				return ErrNoCoursesFound
+			}
+			if polled {
+				if changes := CompareGrades(previous, courses, opts.Filter, c.StatusRules); len(changes) > 0 {
+					if err := opts.OnChanges(changes); err != nil {
+						return err
+					}
+				}
+			}
+			previous, polled = courses, true
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// retryDelay returns how long to wait after the given number of failed polls in a row:
+// twice as long as after the previous one, up to maxBackoff, half of it picked at random
+// so that many watches do not retry in step
+func retryDelay(interval, maxBackoff time.Duration, failures int) time.Duration {
+	delay := min(interval, minRetryDelay)
+	for i := 1; i < failures && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	delay = min(delay, max(maxBackoff, interval))
+
+	half := delay / 2
+	return half + rand.N(half+1)
+}
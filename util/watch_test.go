@@ -0,0 +1,81 @@
+package util_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/patitolabs/gosuv2"
+	"github.com/patitolabs/suvctl/util"
+	"github.com/patitolabs/suvctl/util/suvtest"
+)
+
+// pollBackend fails its second fetch of the grades and publishes unit 2 of course 3403 in
+// the third one
+type pollBackend struct {
+	*suvtest.Backend
+	polls int
+}
+
+func (b *pollBackend) GetSuvGradesResponse(ctx context.Context) (*gosuv2.SuvGradesResponse, error) {
+	response, err := b.Backend.GetSuvGradesResponse(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	b.polls++
+	switch b.polls {
+	case 2:
+		return nil, errors.New("connection reset by peer")
+	case 3:
+		response.Courses[2].Average2 = 15
+	}
+	return response, nil
+}
+
+func TestWatch(t *testing.T) {
+	t.Setenv("SUVCTL_USERCODE", "1023300121")
+	t.Setenv("SUVCTL_PASSWORD", "correct-horse")
+
+	backend := &pollBackend{Backend: suvtest.NewBackend(suvtest.DefaultFixtures())}
+	c := util.NewClient(backend, memoryStore{}, &util.Profile{Name: "default"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var failures int
+	var changes []util.GradeChange
+
+	// Without a session, the first poll logs in with the credentials from the environment
+	err := c.Watch(ctx, util.WatchOptions{
+		Interval:   time.Millisecond,
+		MaxBackoff: 10 * time.Millisecond,
+		OnChanges: func(found []util.GradeChange) error {
+			changes = found
+			cancel()
+			return nil
+		},
+		OnError: func(err error, retry time.Duration) {
+			failures++
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if backend.polls != 3 || failures != 1 {
+		t.Fatalf("got %d polls and %d failures, want 3 and 1", backend.polls, failures)
+	}
+	if len(changes) != 1 || changes[0].CourseID != 3403 || changes[0].Field != "average_2" {
+		t.Fatalf("unexpected changes: %+v", changes)
+	}
+
+	// A session that cannot be renewed stops the watch
+	t.Setenv("SUVCTL_PASSWORD", "")
+	backend.Expire()
+	err = c.Watch(context.Background(), util.WatchOptions{Interval: time.Millisecond})
+	if !errors.Is(err, util.ErrSessionExpired) {
+		t.Fatalf("expired session: got %v", err)
+	}
+}
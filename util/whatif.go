@@ -0,0 +1,174 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/patitolabs/gosuv2"
+)
+
+// maxGrade is the highest grade of the grading scale
+const maxGrade = 20
+
+// Projection is what a course still needs to be passed
+type Projection struct {
+	CourseID   int    `json:"course_id"`
+	CourseName string `json:"course_name"`
+	// Average is the weighted average of the units graded so far
+	Average        float32 `json:"average"`
+	RemainingUnits []int   `json:"remaining_units"`
+	// Needed is the lowest grade needed in every remaining unit
+	Needed float32 `json:"needed"`
+	// SubstituteNeeded is the lowest grade needed on the substitute exam, which replaces
+	// SubstituteUnit, and in every remaining unit, when the exam can still be taken and
+	// would raise the grade
+	SubstituteNeeded float32 `json:"substitute_needed"`
+	SubstituteUnit   int     `json:"substitute_unit"`
+	// Reachable is set when either plan passes the course with grades up to 20
+	Reachable bool   `json:"reachable"`
+	Plan      string `json:"plan"`
+}
+
+// WhatIf projects the grades needed to pass the courses selected by filter, weighting
+// their units with weights, or with the weights from SUV or the grading settings when
+// it is empty
+func (c *Client) WhatIf(ctx context.Context, filter GradeFilter, weights []float32) ([]Projection, error) {
+	if err := ValidateWeights(weights); err != nil {
+		return nil, err
+	}
+
+	suvGradesResponse, err := c.GradesResponse(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	projections := []Projection{}
+	for _, course := range suvGradesResponse.Courses {
+		if filter.matches(course) {
+			projections = append(projections, c.StatusRules.Project(course, weights))
+		}
+	}
+	if len(projections) == 0 && !filter.empty() {
+		return nil, ErrNoCoursesFound
+	}
+
+	return projections, nil
+}
+
+// Project solves for the lowest grade a course needs in its remaining units to reach the
+// passing grade, all of them getting the same grade, and for the lowest grade it needs on
+// the substitute exam, which replaces the lowest unit, along with the remaining units
+func (r StatusRules) Project(course gosuv2.SuvCurrentCourseGrades, weights []float32) Projection {
+	projection := Projection{CourseID: course.CourseID, CourseName: course.CourseName, RemainingUnits: []int{}}
+
+	weights = r.unitWeights(course, weights)
+	averages := unitAverages(course)
+
+	var total, graded, sum float32
+	for i, weight := range weights {
+		total += weight
+		if unitGraded(course, i) {
+			graded += weight
+			sum += weight * averages[i]
+		} else {
+			projection.RemainingUnits = append(projection.RemainingUnits, i+1)
+		}
+	}
+	if graded > 0 {
+		projection.Average = roundHundredths(sum / graded)
+	}
+
+	// The weighted sum of the unit grades that passes once divided by the total weight
+	target := r.MinimumGrade() * total
+	status := r.Evaluate(course)
+	substituteOpen := course.Substitute == 0 && course.Postponed == 0
+
+	switch {
+	case status.Status == StatusPassed:
+		projection.Reachable = true
+		projection.Plan = "already passed"
+	case course.Disabled:
+		projection.Plan = "cannot pass, disqualified in the course"
+	case len(projection.RemainingUnits) > 0:
+		remaining := total - graded
+		projection.Needed = ceilHundredths((target - sum) / remaining)
+		units := describeUnits(projection.RemainingUnits)
+
+		switch {
+		case projection.Needed <= 0:
+			projection.Needed = 0
+			projection.Reachable = true
+			projection.Plan = "passes even with 0 in " + units
+		case projection.Needed <= maxGrade:
+			projection.Reachable = true
+			projection.Plan = fmt.Sprintf("needs %.2f in %s", projection.Needed, units)
+		default:
+			projection.Plan = fmt.Sprintf("cannot pass with %s alone, it would take %.2f in each", units, projection.Needed)
+		}
+
+		if !substituteOpen {
+			break
+		}
+		needed, unit, ok := substituteNeeded(course, weights, averages, target, sum, remaining)
+		if !ok {
+			break
+		}
+		projection.SubstituteNeeded, projection.SubstituteUnit = needed, unit
+		if needed <= maxGrade {
+			join := ", or"
+			if !projection.Reachable {
+				join = ", but can with"
+			}
+			projection.Reachable = true
+			projection.Plan += fmt.Sprintf("%s %.2f in %s and on the substitute exam replacing unit %d", join, needed, units, unit)
+		}
+	case sum >= target:
+		projection.Reachable = true
+		projection.Plan = "passes with the units graded so far"
+	case substituteOpen:
+		needed, unit, _ := substituteNeeded(course, weights, averages, target, sum, 0)
+		projection.SubstituteNeeded, projection.SubstituteUnit = needed, unit
+		if needed <= maxGrade {
+			projection.Reachable = true
+			projection.Plan = fmt.Sprintf("needs %.2f on the substitute exam, replacing unit %d", needed, unit)
+		} else {
+			projection.Plan = fmt.Sprintf("cannot pass with the substitute exam, it would take %.2f", needed)
+		}
+	default:
+		projection.Plan = "cannot pass, " + status.Reason
+	}
+
+	return projection
+}
+
+// substituteNeeded solves for the lowest grade that reaches target on the substitute exam,
+// which replaces the lowest graded unit, the units worth remaining weight getting that
+// grade as well. ok is false when no unit is graded or the exam would not raise the grade.
+func substituteNeeded(course gosuv2.SuvCurrentCourseGrades, weights, averages []float32, target, sum, remaining float32) (needed float32, unit int, ok bool) {
+	lowest := -1
+	for i := range weights {
+		if unitGraded(course, i) && (lowest < 0 || averages[i] < averages[lowest]) {
+			lowest = i
+		}
+	}
+	if lowest < 0 {
+		return 0, 0, false
+	}
+
+	needed = ceilHundredths((target - sum + weights[lowest]*averages[lowest]) / (remaining + weights[lowest]))
+	if needed <= averages[lowest] {
+		return 0, 0, false
+	}
+	return needed, lowest + 1, true
+}
+
+// roundHundredths rounds a grade to the two decimals SUV shows
+func roundHundredths(grade float32) float32 {
+	return float32(math.Round(float64(grade)*100) / 100)
+}
+
+// ceilHundredths rounds a grade up to two decimals, ignoring float32 noise below them
+func ceilHundredths(grade float32) float32 {
+	return float32(math.Ceil(math.Round(float64(grade)*1e4)/100) / 100)
+}
@@ -0,0 +1,71 @@
+package util_test
+
+import (
+	"testing"
+
+	"github.com/patitolabs/gosuv2"
+	"github.com/patitolabs/suvctl/util"
+)
+
+func TestProject(t *testing.T) {
+	cases := []struct {
+		name       string
+		course     gosuv2.SuvCurrentCourseGrades
+		weights    []float32
+		plan       string
+		substitute float32
+	}{
+		{
+			"remaining units",
+			gosuv2.SuvCurrentCourseGrades{Average1: 12, Average2: 14},
+			nil,
+			"needs 14.50 in unit 3, or 13.25 in unit 3 and on the substitute exam replacing unit 1",
+			13.25,
+		},
+		{
+			"given weights",
+			gosuv2.SuvCurrentCourseGrades{Average1: 12, Average2: 14},
+			[]float32{0.25, 0.25, 0.5},
+			"needs 14.00 in unit 3, or 13.34 in unit 3 and on the substitute exam replacing unit 1",
+			13.34,
+		},
+		{
+			"substitute exam not raising the grade",
+			gosuv2.SuvCurrentCourseGrades{Average1: 15, Average2: 16},
+			nil,
+			"needs 9.50 in unit 3",
+			0,
+		},
+		{
+			"only with the substitute exam",
+			gosuv2.SuvCurrentCourseGrades{Average1: 2, Average2: 10},
+			nil,
+			"cannot pass with unit 3 alone, it would take 28.50 in each, but can with 15.25 in unit 3 and on the substitute exam replacing unit 1",
+			15.25,
+		},
+		{
+			"out of reach",
+			gosuv2.SuvCurrentCourseGrades{Average1: 2, Average2: 3},
+			[]float32{0.45, 0.45, 0.1},
+			"cannot pass with unit 3 alone, it would take 112.50 in each",
+			22.1,
+		},
+		{
+			"substitute exam",
+			gosuv2.SuvCurrentCourseGrades{Average1: 9, Average2: 14, Average3: 15, Average: 12.67},
+			nil,
+			"needs 11.50 on the substitute exam, replacing unit 1",
+			11.5,
+		},
+	}
+
+	for _, tc := range cases {
+		got := util.DefaultStatusRules.Project(tc.course, tc.weights)
+		if got.Plan != tc.plan {
+			t.Errorf("%s: got %q, want %q", tc.name, got.Plan, tc.plan)
+		}
+		if got.SubstituteNeeded != tc.substitute {
+			t.Errorf("%s: substitute exam needs %v, want %v", tc.name, got.SubstituteNeeded, tc.substitute)
+		}
+	}
+}